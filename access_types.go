@@ -1,5 +1,11 @@
 package fabric
 
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
 // AccessType is the interface to define how an access procedure should
 // behave; Create an Access Procedure function signature type and add
 // these methods to it.
@@ -15,6 +21,111 @@ type AccessType interface {
 	// InvariantEdge(*Edge) bool                  // used to calculate if a CDS edge should remain invariant
 }
 
+// Validator can be satisfied by Access Types that can check whether they
+// are runnable against a CDS before they are executed (e.g. that the CDS
+// node a procedure deletes exists); see Graph.Precheck
+type Validator interface {
+	Validate(CDS) error
+}
+
+// Precheck validates every access procedure of every node (in order of node
+// id) that satisfies Validator against a CDS, and returns all failures.
+func (g *Graph) Precheck(c CDS) []error {
+	errs := make([]error, 0)
+
+	var nodes []DGNode
+	for n := range g.Top {
+		nodes = append(nodes, n)
+	}
+	sortNodes(nodes)
+
+	for _, n := range nodes {
+		for _, p := range n.ListProcedures() {
+			v, ok := p.(Validator)
+			if !ok {
+				continue
+			}
+			if err := v.Validate(c); err != nil {
+				errs = append(errs, fmt.Errorf("Node %d access procedure %d: %v", n.ID(), p.ID(), err))
+			}
+		}
+	}
+
+	return errs
+}
+
+// ProgressReporter can be satisfied by Access Types that report how much of
+// their work (from 0 to 1) they have done (see WithTimeout)
+type ProgressReporter interface {
+	Progress() float64
+}
+
+// timeoutAccess is an Access Type whose Commit is limited to a duration
+type timeoutAccess struct {
+	AccessType
+	d time.Duration
+}
+
+// WithTimeout wraps an Access Type so that Commit returns an error if the
+// procedure does not finish within d, in which case the node is signaled
+// PartialAbort if the procedure reported any progress (see ProgressReporter)
+// or Aborted otherwise (in the background, so Commit returns without waiting
+// for the dependents to receive the signal).
+// NOTE: the procedure's Commit keeps running in the background after a
+// timeout; it should check whether it has timed out before modifying the CDS.
+func WithTimeout(p AccessType, d time.Duration) AccessType {
+	return &timeoutAccess{
+		AccessType: p,
+		d:          d,
+	}
+}
+
+// Commit runs the wrapped procedure's Commit with a deadline
+func (t *timeoutAccess) Commit(n DGNode) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- t.AccessType.Commit(n)
+	}()
+
+	timer := time.NewTimer(t.d)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	}
+
+	value := Aborted
+	if r, ok := t.AccessType.(ProgressReporter); ok && r.Progress() > 0 {
+		value = PartialAbort
+	}
+	// the dependents may not be receiving, so the timeout is reported right away
+	go n.Signal(NodeSignal{
+		AccessType: t.ID(),
+		Value:      value,
+	})
+
+	return fmt.Errorf("Access procedure %d timed out after %v", t.ID(), t.d)
+}
+
+// Validate validates the wrapped procedure (see Validator); procedures that
+// can not be validated are always valid
+func (t *timeoutAccess) Validate(c CDS) error {
+	if v, ok := t.AccessType.(Validator); ok {
+		return v.Validate(c)
+	}
+	return nil
+}
+
+// Progress returns the progress of the wrapped procedure (0 if it does not report any)
+func (t *timeoutAccess) Progress() float64 {
+	if r, ok := t.AccessType.(ProgressReporter); ok {
+		return r.Progress()
+	}
+	return 0
+}
+
 // RestoreNodes is a list of Node values that can be used to overwrite existing
 // Node values after an operation failure.
 type RestoreNodes []Node
@@ -32,3 +143,163 @@ type RestoreEdges []Edge
 
 // ProcedureList ...
 type ProcedureList []AccessType
+
+// PriorityStrategy defines how the priorities of a set of access
+// procedures are combined into a single priority value
+type PriorityStrategy int
+
+const (
+	// MaxPriority uses the highest procedure priority
+	MaxPriority PriorityStrategy = iota
+	// SumPriority adds all procedure priorities together
+	SumPriority
+	// CountPriority uses the number of procedures as the priority
+	CountPriority
+)
+
+// AggregatePriority computes a single priority from the Priority() values of
+// all procedures in a ProcedureList; useful for implementing a DGNode's
+// GetPriority() method in a well-defined way.
+func AggregatePriority(pl ProcedureList, strategy PriorityStrategy) int {
+	switch strategy {
+	case MaxPriority:
+		max := 0
+		for i, p := range pl {
+			if i == 0 || p.Priority() > max {
+				max = p.Priority()
+			}
+		}
+		return max
+	case SumPriority:
+		sum := 0
+		for _, p := range pl {
+			sum += p.Priority()
+		}
+		return sum
+	case CountPriority:
+		return len(pl)
+	}
+
+	return 0
+}
+
+// ProcedureGraph is a dependency graph over the access procedures of a single
+// DGNode; it is used to order a node's procedures relative to each other, while
+// the node's Graph orders the node relative to other nodes.
+type ProcedureGraph struct {
+	Procedures ProcedureList
+	Top        map[int][]int // access type id to the ids of the access types it must run after
+}
+
+// NewProcedureGraph creates a ProcedureGraph with no ordering constraints
+func NewProcedureGraph(pl ProcedureList) *ProcedureGraph {
+	return &ProcedureGraph{
+		Procedures: pl,
+		Top:        make(map[int][]int),
+	}
+}
+
+// contains checks whether an access type id is in the procedure graph
+func (p *ProcedureGraph) contains(id int) bool {
+	for _, at := range p.Procedures {
+		if at.ID() == id {
+			return true
+		}
+	}
+	return false
+}
+
+// AddOrder specifies that the access procedure with id 'after' must run
+// after the access procedure with id 'before'
+func (p *ProcedureGraph) AddOrder(before, after int) error {
+	if !p.contains(before) {
+		return fmt.Errorf("Access type %d is not in procedure list", before)
+	}
+	if !p.contains(after) {
+		return fmt.Errorf("Access type %d is not in procedure list", after)
+	}
+
+	for _, id := range p.Top[after] {
+		if id == before {
+			return nil
+		}
+	}
+	p.Top[after] = append(p.Top[after], before)
+
+	return nil
+}
+
+// TopoSort returns the access procedures in an order that satisfies every
+// ordering constraint (ties keep their procedure list order); it will return
+// an error if the constraints contain a cycle.
+func (p *ProcedureGraph) TopoSort() ([]AccessType, error) {
+	order := make([]AccessType, 0, len(p.Procedures))
+	done := make(map[int]bool)
+
+	for len(order) < len(p.Procedures) {
+		progress := false
+		for _, at := range p.Procedures {
+			if done[at.ID()] {
+				continue
+			}
+
+			ready := true
+			for _, id := range p.Top[at.ID()] {
+				if !done[id] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				done[at.ID()] = true
+				order = append(order, at)
+				progress = true
+				break
+			}
+		}
+
+		if !progress {
+			return order, fmt.Errorf("Procedure ordering: %w", ErrCycle)
+		}
+	}
+
+	return order, nil
+}
+
+// OrderedProcedures can be satisfied by DGNodes whose access procedures must
+// run in a specific order (e.g. by using a ProcedureGraph)
+type OrderedProcedures interface {
+	ProcedureOrder() ([]AccessType, error)
+}
+
+// ProcedureOrder returns the order in which a node's access procedures should
+// run; nodes that do not satisfy OrderedProcedures run them in list order
+func ProcedureOrder(n DGNode) ([]AccessType, error) {
+	if o, ok := n.(OrderedProcedures); ok {
+		return o.ProcedureOrder()
+	}
+
+	return []AccessType(n.ListProcedures()), nil
+}
+
+// CommitProcedures commits a node's access procedures in ProcedureOrder,
+// stopping at the first error or once the context is done; it is the work
+// Run executes when none is given.
+func CommitProcedures(ctx context.Context, n DGNode) error {
+	order, err := ProcedureOrder(n)
+	if err != nil {
+		return fmt.Errorf("Node %d: %w", n.ID(), err)
+	}
+
+	for _, p := range order {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := p.Commit(n); err != nil {
+			return fmt.Errorf("Node %d access procedure %d: %w", n.ID(), p.ID(), err)
+		}
+	}
+
+	return nil
+}
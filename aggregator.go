@@ -0,0 +1,119 @@
+package fabric
+
+// AggregatorNode is a ready-made join point for a dependency graph: it waits
+// for a terminal signal from every one of its dependencies, combines them
+// into a single derived signal and forwards that signal to its dependents.
+type AggregatorNode struct {
+	Id               int
+	Type             NodeType
+	Priority         int
+	AccessProcedures *ProcedureList
+	Signalers        *SignalingMap
+	Signals          *SignalsMap
+}
+
+// NewAggregatorNode ...
+func NewAggregatorNode(id int, t NodeType) *AggregatorNode {
+	sm := make(SignalingMap, 0)
+	s := make(SignalsMap, 0)
+	p := make(ProcedureList, 0)
+
+	return &AggregatorNode{
+		Id:               id,
+		Type:             t,
+		Signalers:        &sm,
+		Signals:          &s,
+		AccessProcedures: &p,
+	}
+}
+
+// ID ...
+func (a *AggregatorNode) ID() int {
+	return a.Id
+}
+
+// GetType ...
+func (a *AggregatorNode) GetType() NodeType {
+	return a.Type
+}
+
+// GetPriority ...
+func (a *AggregatorNode) GetPriority() int {
+	return a.Priority
+}
+
+// ListProcedures ...
+func (a *AggregatorNode) ListProcedures() ProcedureList {
+	return *a.AccessProcedures
+}
+
+// ListSignals ...
+func (a *AggregatorNode) ListSignals() SignalsMap {
+	return *a.Signals
+}
+
+// ListSignalers ...
+func (a *AggregatorNode) ListSignalers() SignalingMap {
+	return *a.Signalers
+}
+
+// UpdateSignaling ...
+func (a *AggregatorNode) UpdateSignaling(sm SignalingMap, s SignalsMap) {
+	*a.Signalers = sm
+	*a.Signals = s
+}
+
+// Signal ...
+func (a *AggregatorNode) Signal(s NodeSignal) {
+	for _, c := range *a.Signalers {
+		c <- s
+	}
+}
+
+// Join blocks until every dependency has sent a terminal signal, then signals
+// the derived value to all dependents and returns it. The derived signal is
+// Aborted if any dependency aborted, PartialAbort if any dependency partially
+// aborted, and Completed otherwise.
+func (a *AggregatorNode) Join() Signal {
+	results := make(chan Signal)
+	count := 0
+	for _, c := range *a.Signals {
+		if c == nil {
+			continue
+		}
+		count++
+		go func(c <-chan NodeSignal) {
+			for sig := range c {
+				if sig.IsTerminal() {
+					// terminal custom values count as completions
+					v, ok := sig.Effective().(Signal)
+					if !ok {
+						v = Completed
+					}
+					results <- v
+					return
+				}
+			}
+			// a closed channel counts as an abort
+			results <- Aborted
+		}(c)
+	}
+
+	derived := Completed
+	for i := 0; i < count; i++ {
+		switch <-results {
+		case Aborted:
+			derived = Aborted
+		case PartialAbort:
+			if derived != Aborted {
+				derived = PartialAbort
+			}
+		}
+	}
+
+	a.Signal(NodeSignal{
+		Value: derived,
+	})
+
+	return derived
+}
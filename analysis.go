@@ -0,0 +1,990 @@
+package fabric
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sort"
+)
+
+// nodesByID maps node ids to the keys of Top; adjacency lists may hold
+// values that are not the Top keys themselves so lookups are resolved
+// through node ids.
+func (g *Graph) nodesByID() map[int]DGNode {
+	keys := make(map[int]DGNode)
+	for n := range g.Top {
+		keys[n.ID()] = n
+	}
+	return keys
+}
+
+// ForEachEdge calls fn for every dependency edge in the graph (source node,
+// destination node) in sorted order of source id then destination id, and
+// stops early if fn returns false. Destinations are resolved to the nodes in
+// the graph where possible.
+func (g *Graph) ForEachEdge(fn func(src, dst DGNode) bool) {
+	var sources []DGNode
+	for n := range g.Top {
+		sources = append(sources, n)
+	}
+	sortNodes(sources)
+
+	for _, n := range sources {
+		dests := make([]DGNode, len(g.Top[n]))
+		copy(dests, g.Top[n])
+		sortNodes(dests)
+
+		for _, d := range dests {
+			if k, ok := g.GetNode(d.ID()); ok {
+				d = k
+			}
+			if !fn(n, d) {
+				return
+			}
+		}
+	}
+}
+
+// Reachable checks whether the node with id 'to' can be reached from the
+// node with id 'from' by following dependency edges i.e. whether 'from'
+// (transitively) depends on 'to'
+func (g *Graph) Reachable(from, to int) bool {
+	return g.reachable(from, to)
+}
+
+// reachable checks whether the node with id 'to' can be reached from the
+// node with id 'from' by following dependency edges (skipping the direct
+// edges from 'from' to any id in 'skip')
+func (g *Graph) reachable(from, to int, skip ...int) bool {
+	start, ok := g.GetNode(from)
+	if !ok {
+		return false
+	}
+
+	visited := map[int]bool{from: true}
+	var stack []DGNode
+	for _, d := range g.Top[start] {
+		skipped := false
+		for _, id := range skip {
+			if d.ID() == id {
+				skipped = true
+			}
+		}
+		if !skipped {
+			stack = append(stack, d)
+		}
+	}
+
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if n.ID() == to {
+			return true
+		}
+		if visited[n.ID()] {
+			continue
+		}
+		visited[n.ID()] = true
+
+		k, ok := g.GetNode(n.ID())
+		if !ok {
+			continue
+		}
+		stack = append(stack, g.Top[k]...)
+	}
+
+	return false
+}
+
+// TraversalOrder specifies when a depth-first traversal visits a node
+// relative to its dependencies
+type TraversalOrder int
+
+const (
+	// PreOrder visits a node before its dependencies
+	PreOrder TraversalOrder = iota
+	// PostOrder visits a node after all of its dependencies i.e. in dependency-completion order
+	PostOrder
+)
+
+// DFS does a pre-order depth-first traversal (see DFSOrder)
+func (g *Graph) DFS(startID int, visit func(DGNode) bool) {
+	g.DFSOrder(startID, PreOrder, visit)
+}
+
+// DFSOrder does a depth-first traversal from the node with the given id
+// towards its dependencies (in order of node id), visiting every reachable
+// node once in the given order; the traversal stops if visit returns false.
+func (g *Graph) DFSOrder(startID int, order TraversalOrder, visit func(DGNode) bool) {
+	start, ok := g.GetNode(startID)
+	if !ok {
+		return
+	}
+
+	g.dfs(start, order, make(map[int]bool), visit)
+}
+
+// Recursive Depth-First-Search; returns false once the traversal has been stopped
+func (g *Graph) dfs(n DGNode, order TraversalOrder, visited map[int]bool, visit func(DGNode) bool) bool {
+	visited[n.ID()] = true
+
+	if order == PreOrder && !visit(n) {
+		return false
+	}
+
+	deps := g.Dependencies(n)
+	sortNodes(deps)
+	for _, d := range deps {
+		k, ok := g.GetNode(d.ID())
+		if !ok || visited[k.ID()] {
+			continue
+		}
+		if !g.dfs(k, order, visited, visit) {
+			return false
+		}
+	}
+
+	if order == PostOrder {
+		return visit(n)
+	}
+
+	return true
+}
+
+// Roots returns every root boundary node in the graph (i.e. all nodes
+// that have no dependents).
+func (g *Graph) Roots() []DGNode {
+	var list []DGNode
+
+	for n := range g.Top {
+		if g.IsRootBoundary(n) {
+			list = append(list, n)
+		}
+	}
+
+	sortNodes(list)
+	return list
+}
+
+// UnreachableFromRoots will do a multi-source BFS from all root boundary
+// nodes (walking towards their dependencies) and return every node that
+// was never visited. In a well-formed DDAG this list is empty; any nodes
+// returned belong to isolated cycles that have no root entry point.
+func (g *Graph) UnreachableFromRoots() []DGNode {
+	keys := g.nodesByID()
+	visited := make(map[int]bool)
+	queue := g.Roots()
+	for _, r := range queue {
+		visited[r.ID()] = true
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, d := range g.Top[keys[n.ID()]] {
+			k, ok := keys[d.ID()]
+			if !ok || visited[k.ID()] {
+				continue
+			}
+			visited[k.ID()] = true
+			queue = append(queue, k)
+		}
+	}
+
+	var list []DGNode
+	for id, n := range keys {
+		if !visited[id] {
+			list = append(list, n)
+		}
+	}
+
+	sortNodes(list)
+	return list
+}
+
+// AdjacencyMatrix returns a boolean adjacency matrix of the graph along with
+// the node id ordering used for its rows and columns (sorted ascending).
+// matrix[i][j] is true when there is an edge from node ids[i] to its
+// dependency ids[j].
+func (g *Graph) AdjacencyMatrix() ([][]bool, []int) {
+	var nodes []DGNode
+	for n := range g.Top {
+		nodes = append(nodes, n)
+	}
+	sortNodes(nodes)
+
+	ids := make([]int, len(nodes))
+	pos := make(map[int]int)
+	for i, n := range nodes {
+		ids[i] = n.ID()
+		pos[n.ID()] = i
+	}
+
+	matrix := make([][]bool, len(nodes))
+	for i := range nodes {
+		matrix[i] = make([]bool, len(nodes))
+	}
+
+	g.ForEachEdge(func(src, dst DGNode) bool {
+		if j, ok := pos[dst.ID()]; ok {
+			matrix[pos[src.ID()]][j] = true
+		}
+		return true
+	})
+
+	return matrix, ids
+}
+
+// RedundantEdges returns every edge (source id, destination id) whose
+// destination is already reachable from its source through a longer path,
+// i.e. the edges a transitive reduction would drop. Removing them does not
+// change the dependency semantics of the graph but cuts the number of
+// signaling channels. The graph itself is left untouched.
+func (g *Graph) RedundantEdges() [][2]int {
+	var edges [][2]int
+	g.ForEachEdge(func(src, dst DGNode) bool {
+		if g.reachable(src.ID(), dst.ID(), dst.ID()) {
+			edges = append(edges, [2]int{src.ID(), dst.ID()})
+		}
+		return true
+	})
+
+	return edges
+}
+
+// DanglingEdges returns every edge (source id, destination id) whose
+// destination is no longer present as a node in the graph; e.g. after a
+// node has been removed without removing the edges pointing to it.
+func (g *Graph) DanglingEdges() [][2]int {
+	var edges [][2]int
+
+	g.ForEachEdge(func(src, dst DGNode) bool {
+		if _, ok := g.GetNode(dst.ID()); !ok {
+			edges = append(edges, [2]int{src.ID(), dst.ID()})
+		}
+		return true
+	})
+
+	return edges
+}
+
+// PriorityInversions returns every (high id, low id) pair where a node
+// depends on a node with a strictly lower priority (see GetPriority); the
+// priority ordering example in poset.go implies this should never happen.
+func (g *Graph) PriorityInversions() [][2]int {
+	var pairs [][2]int
+
+	g.ForEachEdge(func(src, dst DGNode) bool {
+		if d, ok := g.GetNode(dst.ID()); ok && src.GetPriority() > d.GetPriority() {
+			pairs = append(pairs, [2]int{src.ID(), d.ID()})
+		}
+		return true
+	})
+
+	return pairs
+}
+
+// TopoSort returns all graph nodes in execution order (every node comes
+// after all of its dependencies), breaking ties by lowest node id; it will
+// return an error if the graph contains a cycle.
+func (g *Graph) TopoSort() ([]DGNode, error) {
+	order := make([]DGNode, 0, len(g.Top))
+
+	err := g.topoWalk(func(n DGNode) bool {
+		order = append(order, n)
+		return true
+	})
+
+	return order, err
+}
+
+// TopoSortFrom is the same as TopoSort but only orders the nodes that are
+// not completed, treating completed nodes as satisfied dependencies, so that
+// processing a graph can be resumed without redoing finished work; it will
+// return an error if the remaining nodes contain a cycle.
+func (g *Graph) TopoSortFrom(completed map[int]bool) ([]DGNode, error) {
+	order := make([]DGNode, 0)
+
+	err := g.topoWalkFrom(completed, func(n DGNode) bool {
+		order = append(order, n)
+		return true
+	})
+
+	return order, err
+}
+
+// TopoStream emits all graph nodes in the same order as TopoSort over a
+// channel as they become ready, rather than materializing the whole order
+// at once. The node channel is closed when done; a cycle error (or the
+// context's error if it is cancelled) is sent on the error channel.
+// NOTE: the graph must not be modified while the stream is being consumed.
+func (g *Graph) TopoStream(ctx context.Context) (<-chan DGNode, <-chan error) {
+	nodes := make(chan DGNode)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(nodes)
+		defer close(errs)
+
+		err := g.topoWalk(func(n DGNode) bool {
+			select {
+			case nodes <- n:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+
+		if ctx.Err() != nil {
+			errs <- ctx.Err()
+		} else if err != nil {
+			errs <- err
+		}
+	}()
+
+	return nodes, errs
+}
+
+// topoWalk calls visit for every graph node in execution order (breaking
+// ties by lowest node id) until visit returns false; it will return an
+// error if the graph contains a cycle.
+func (g *Graph) topoWalk(visit func(DGNode) bool) error {
+	return g.topoWalkFrom(nil, visit)
+}
+
+// topoWalkFrom is the same as topoWalk but skips the completed nodes,
+// treating them as satisfied dependencies
+func (g *Graph) topoWalkFrom(completed map[int]bool, visit func(DGNode) bool) error {
+	keys := g.nodesByID()
+	for id, done := range completed {
+		if done {
+			delete(keys, id)
+		}
+	}
+
+	// count each node's (distinct, existing) dependencies and record dependents
+	remaining := make(map[int]int)
+	dependents := make(map[int][]int)
+	for n, l := range g.Top {
+		seen := make(map[int]bool)
+		for _, d := range l {
+			if _, ok := keys[d.ID()]; !ok || seen[d.ID()] {
+				continue
+			}
+			seen[d.ID()] = true
+			remaining[n.ID()]++
+			dependents[d.ID()] = append(dependents[d.ID()], n.ID())
+		}
+	}
+
+	var ready []int
+	for id := range keys {
+		if remaining[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	visited := 0
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		id := ready[0]
+		ready = ready[1:]
+		visited++
+		if !visit(keys[id]) {
+			return nil
+		}
+
+		for _, dep := range dependents[id] {
+			remaining[dep]--
+			if remaining[dep] == 0 {
+				ready = append(ready, dep)
+			}
+		}
+	}
+
+	if visited != len(keys) {
+		return ErrCycle
+	}
+
+	return nil
+}
+
+// Spine returns the longest path (by node count) through the graph, in
+// execution order: starting from a leaf boundary node and ending at a root
+// boundary node. It will return an error if the graph contains a cycle.
+func (g *Graph) Spine() ([]DGNode, error) {
+	var spine []DGNode
+
+	order, err := g.TopoSort()
+	if err != nil {
+		return spine, err
+	}
+
+	length := make(map[int]int)
+	prev := make(map[int]DGNode)
+	var end DGNode
+	for _, n := range order {
+		length[n.ID()] = 1
+		for _, d := range g.Top[n] {
+			l, ok := length[d.ID()]
+			if !ok {
+				continue
+			}
+			p, set := prev[n.ID()]
+			if l+1 > length[n.ID()] || (l+1 == length[n.ID()] && set && d.ID() < p.ID()) {
+				length[n.ID()] = l + 1
+				prev[n.ID()] = d
+			}
+		}
+		if end == nil || length[n.ID()] > length[end.ID()] {
+			end = n
+		}
+	}
+
+	keys := g.nodesByID()
+	for n := end; n != nil; {
+		spine = append([]DGNode{keys[n.ID()]}, spine...)
+		p, ok := prev[n.ID()]
+		if !ok {
+			break
+		}
+		n = p
+	}
+
+	return spine, nil
+}
+
+// GraphStats is a summary of the size and shape of a graph
+type GraphStats struct {
+	Nodes  int              // number of nodes
+	Edges  int              // number of dependency edges
+	Roots  int              // number of root boundary nodes
+	Leaves int              // number of leaf boundary nodes
+	Types  map[NodeType]int // number of nodes of each type
+	VDGs   int              // number of VDGs attached to the graph
+}
+
+// Stats returns a summary of the size and shape of the graph
+func (g *Graph) Stats() GraphStats {
+	stats := GraphStats{
+		Nodes: len(g.Top),
+		Types: make(map[NodeType]int),
+		VDGs:  len(g.VDG),
+	}
+
+	for n, l := range g.Top {
+		stats.Edges += len(l)
+		stats.Types[n.GetType()]++
+		if len(l) == 0 {
+			stats.Leaves++
+		}
+		if g.IsRootBoundary(n) {
+			stats.Roots++
+		}
+	}
+
+	return stats
+}
+
+// fanOuts returns the out-degree (number of distinct dependencies) of every graph node
+func (g *Graph) fanOuts() map[int]int {
+	fanOut := make(map[int]int)
+	for n := range g.Top {
+		fanOut[n.ID()] = len(g.dependencyIDs(n.ID()))
+	}
+	return fanOut
+}
+
+// FanOutStats returns the minimum, maximum, mean and (population) standard
+// deviation of the out-degrees (number of dependencies) of the graph's
+// nodes; all are zero for an empty graph.
+func (g *Graph) FanOutStats() (min, max, mean, stddev float64) {
+	fanOut := g.fanOuts()
+	if len(fanOut) == 0 {
+		return 0, 0, 0, 0
+	}
+
+	min = math.Inf(1)
+	max = math.Inf(-1)
+	for _, d := range fanOut {
+		min = math.Min(min, float64(d))
+		max = math.Max(max, float64(d))
+		mean += float64(d)
+	}
+	mean /= float64(len(fanOut))
+
+	for _, d := range fanOut {
+		stddev += (float64(d) - mean) * (float64(d) - mean)
+	}
+	stddev = math.Sqrt(stddev / float64(len(fanOut)))
+
+	return min, max, mean, stddev
+}
+
+// SkewedNodes returns the sorted ids of the nodes whose fan-out exceeds
+// mean + factor*stddev (see FanOutStats); such nodes are likely scheduling
+// and signaling bottlenecks.
+func (g *Graph) SkewedNodes(factor float64) []int {
+	_, _, mean, stddev := g.FanOutStats()
+	threshold := mean + factor*stddev
+
+	ids := make([]int, 0)
+	for id, d := range g.fanOuts() {
+		if float64(d) > threshold {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Ints(ids)
+	return ids
+}
+
+// FeedbackEdgeSet returns a (heuristically) minimal set of edges (source id,
+// destination id) whose removal makes the graph acyclic. It uses the greedy
+// ordering heuristic of Eades, Lin and Smyth: nodes are ordered so that as
+// many edges as possible point forwards, and every edge pointing backwards
+// in that ordering is part of the feedback set.
+func (g *Graph) FeedbackEdgeSet() [][2]int {
+	out := make(map[int]map[int]bool)
+	in := make(map[int]map[int]bool)
+	var ids []int
+	for n := range g.Top {
+		out[n.ID()] = make(map[int]bool)
+		in[n.ID()] = make(map[int]bool)
+		ids = append(ids, n.ID())
+	}
+	sort.Ints(ids)
+
+	var edges [][2]int
+	g.ForEachEdge(func(src, dst DGNode) bool {
+		if _, ok := out[dst.ID()]; ok {
+			edges = append(edges, [2]int{src.ID(), dst.ID()})
+			if src.ID() != dst.ID() {
+				out[src.ID()][dst.ID()] = true
+				in[dst.ID()][src.ID()] = true
+			}
+		}
+		return true
+	})
+
+	remaining := make(map[int]bool)
+	for _, id := range ids {
+		remaining[id] = true
+	}
+	remove := func(id int) {
+		delete(remaining, id)
+		for d := range out[id] {
+			delete(in[d], id)
+		}
+		for s := range in[id] {
+			delete(out[s], id)
+		}
+	}
+
+	var head, tail []int
+	for len(remaining) > 0 {
+		changed := true
+		for changed {
+			changed = false
+			for _, id := range ids {
+				if !remaining[id] {
+					continue
+				}
+				if len(out[id]) == 0 {
+					tail = append([]int{id}, tail...)
+					remove(id)
+					changed = true
+				} else if len(in[id]) == 0 {
+					head = append(head, id)
+					remove(id)
+					changed = true
+				}
+			}
+		}
+
+		best, delta := 0, 0
+		found := false
+		for _, id := range ids {
+			if !remaining[id] {
+				continue
+			}
+			if d := len(out[id]) - len(in[id]); !found || d > delta {
+				best, delta, found = id, d, true
+			}
+		}
+		if found {
+			head = append(head, best)
+			remove(best)
+		}
+	}
+
+	pos := make(map[int]int)
+	for i, id := range append(head, tail...) {
+		pos[id] = i
+	}
+
+	var feedback [][2]int
+	for _, e := range edges {
+		if pos[e[0]] >= pos[e[1]] {
+			feedback = append(feedback, e)
+		}
+	}
+
+	sortEdges(feedback)
+	return feedback
+}
+
+// MinCut returns a minimum set of edges (source id, destination id) whose
+// removal disconnects node 'to' from node 'from' (following edges from
+// dependent to dependency), using max-flow/min-cut on unit edge capacities.
+// These are the single points of failure of the signal propagation between
+// the two nodes. The result is empty if 'to' is not reachable from 'from'.
+func (g *Graph) MinCut(from, to int) [][2]int {
+	cut := make([][2]int, 0)
+
+	keys := g.nodesByID()
+	if _, ok := keys[from]; !ok || from == to {
+		return cut
+	}
+	if _, ok := keys[to]; !ok {
+		return cut
+	}
+
+	// residual capacities of the (distinct) edges between existing nodes
+	capacity := make(map[int]map[int]int)
+	edges := make(map[[2]int]bool)
+	g.ForEachEdge(func(src, dst DGNode) bool {
+		e := [2]int{src.ID(), dst.ID()}
+		if _, ok := keys[dst.ID()]; !ok || e[0] == e[1] || edges[e] {
+			return true
+		}
+		edges[e] = true
+
+		for _, id := range e {
+			if capacity[id] == nil {
+				capacity[id] = make(map[int]int)
+			}
+		}
+		capacity[e[0]][e[1]]++
+		return true
+	})
+
+	// search returns the nodes reachable from 'from' in the residual graph
+	// along with the parent of each node on its (shortest) path
+	search := func() map[int]int {
+		parent := map[int]int{from: from}
+		queue := []int{from}
+		for len(queue) > 0 {
+			u := queue[0]
+			queue = queue[1:]
+
+			var next []int
+			for v := range capacity[u] {
+				next = append(next, v)
+			}
+			sort.Ints(next)
+
+			for _, v := range next {
+				if _, seen := parent[v]; seen || capacity[u][v] <= 0 {
+					continue
+				}
+				parent[v] = u
+				queue = append(queue, v)
+			}
+		}
+		return parent
+	}
+
+	// Edmonds-Karp: augment along shortest paths until 'to' is unreachable
+	for {
+		parent := search()
+		if _, ok := parent[to]; !ok {
+			break
+		}
+		for v := to; v != from; v = parent[v] {
+			capacity[parent[v]][v]--
+			capacity[v][parent[v]]++
+		}
+	}
+
+	// the cut is every edge leaving the residual-reachable side
+	reached := search()
+	for e := range edges {
+		_, in := reached[e[0]]
+		_, out := reached[e[1]]
+		if in && !out {
+			cut = append(cut, e)
+		}
+	}
+	sortEdges(cut)
+
+	return cut
+}
+
+// isoNode is the structural signature of a node used when matching graphs
+type isoNode struct {
+	t        NodeType
+	priority int
+	out, in  int
+}
+
+// structure returns the (distinct) edges between existing nodes of a graph
+// along with the structural signature of every node
+func (g *Graph) structure() (map[[2]int]bool, map[int]isoNode) {
+	keys := g.nodesByID()
+	edges := make(map[[2]int]bool)
+	sigs := make(map[int]isoNode)
+
+	for id, n := range keys {
+		sigs[id] = isoNode{t: n.GetType(), priority: n.GetPriority()}
+	}
+
+	g.ForEachEdge(func(src, dst DGNode) bool {
+		e := [2]int{src.ID(), dst.ID()}
+		if _, ok := keys[e[1]]; ok && !edges[e] {
+			edges[e] = true
+
+			s := sigs[e[0]]
+			s.out++
+			sigs[e[0]] = s
+			d := sigs[e[1]]
+			d.in++
+			sigs[e[1]] = d
+		}
+		return true
+	})
+
+	return edges, sigs
+}
+
+// Isomorphic checks whether there is a bijection between the nodes of two
+// graphs that preserves node types, priorities and edges; i.e. whether two
+// graphs are structurally identical regardless of their node ids.
+func Isomorphic(a, b *Graph) bool {
+	aEdges, aSigs := a.structure()
+	bEdges, bSigs := b.structure()
+
+	if len(aSigs) != len(bSigs) || len(aEdges) != len(bEdges) {
+		return false
+	}
+
+	counts := make(map[isoNode]int)
+	for _, s := range aSigs {
+		counts[s]++
+	}
+	for _, s := range bSigs {
+		counts[s]--
+	}
+	for _, c := range counts {
+		if c != 0 {
+			return false
+		}
+	}
+
+	aIDs := make([]int, 0, len(aSigs))
+	for id := range aSigs {
+		aIDs = append(aIDs, id)
+	}
+	sort.Ints(aIDs)
+	bIDs := make([]int, 0, len(bSigs))
+	for id := range bSigs {
+		bIDs = append(bIDs, id)
+	}
+	sort.Ints(bIDs)
+
+	mapping := make(map[int]int)
+	used := make(map[int]bool)
+
+	var match func(i int) bool
+	match = func(i int) bool {
+		if i == len(aIDs) {
+			return true
+		}
+
+		x := aIDs[i]
+		for _, y := range bIDs {
+			if used[y] || aSigs[x] != bSigs[y] {
+				continue
+			}
+			if aEdges[[2]int{x, x}] != bEdges[[2]int{y, y}] {
+				continue
+			}
+
+			// edges to and from every node mapped so far must correspond
+			consistent := true
+			for m, fm := range mapping {
+				if aEdges[[2]int{x, m}] != bEdges[[2]int{y, fm}] || aEdges[[2]int{m, x}] != bEdges[[2]int{fm, y}] {
+					consistent = false
+					break
+				}
+			}
+			if !consistent {
+				continue
+			}
+
+			mapping[x] = y
+			used[y] = true
+			if match(i + 1) {
+				return true
+			}
+			delete(mapping, x)
+			delete(used, y)
+		}
+
+		return false
+	}
+
+	return match(0)
+}
+
+// BetweennessCentrality returns the (unnormalized) betweenness of every node
+// i.e. the sum, over all ordered pairs of other nodes, of the fraction of
+// shortest dependency paths between them that pass through the node. Nodes
+// with a high betweenness relay the most signals. It uses Brandes' algorithm
+// over the (distinct) edges in the graph.
+func (g *Graph) BetweennessCentrality() map[int]float64 {
+	edges, _ := g.structure()
+
+	adj := make(map[int][]int)
+	ids := make([]int, 0)
+	for n := range g.Top {
+		ids = append(ids, n.ID())
+	}
+	sort.Ints(ids)
+	for e := range edges {
+		adj[e[0]] = append(adj[e[0]], e[1])
+	}
+
+	cb := make(map[int]float64)
+	for _, id := range ids {
+		cb[id] = 0
+	}
+
+	for _, s := range ids {
+		var stack []int
+		preds := make(map[int][]int)
+		sigma := map[int]float64{s: 1}
+		dist := map[int]int{s: 0}
+
+		queue := []int{s}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+
+			for _, w := range adj[v] {
+				if _, ok := dist[w]; !ok {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+
+		delta := make(map[int]float64)
+		for len(stack) > 0 {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				cb[w] += delta[w]
+			}
+		}
+	}
+
+	return cb
+}
+
+// Hash returns a fingerprint of the graph computed over its node ids (with
+// their types and priorities) and its edges, in sorted order, so that graphs
+// with the same nodes and edges always hash equal regardless of the order
+// they were built in (e.g. for use as a cache key).
+func (g *Graph) Hash() uint64 {
+	keys := g.nodesByID()
+	ids := make([]int, 0, len(keys))
+	for id := range keys {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	write := func(v int) {
+		binary.LittleEndian.PutUint64(buf, uint64(v))
+		h.Write(buf)
+	}
+
+	write(len(ids))
+	for _, id := range ids {
+		write(id)
+		write(int(keys[id].GetType()))
+		write(keys[id].GetPriority())
+	}
+
+	for _, id := range ids {
+		// duplicate edges are the same edge
+		deps := g.dependencyIDs(id)
+		sort.Ints(deps)
+		distinct := deps[:0]
+		for j, d := range deps {
+			if j == 0 || deps[j-1] != d {
+				distinct = append(distinct, d)
+			}
+		}
+
+		write(len(distinct))
+		for _, d := range distinct {
+			write(d)
+		}
+	}
+
+	return h.Sum64()
+}
+
+// IsForest checks whether the dependency structure of the graph is a forest:
+// it is acyclic and every node has at most one dependency
+func (g *Graph) IsForest() bool {
+	for n := range g.Top {
+		if len(g.distinctDependencies(n.ID())) > 1 {
+			return false
+		}
+	}
+
+	return !g.CycleDetect()
+}
+
+// IsTree checks whether the dependency structure of the graph is a single
+// (non-empty) tree: a forest (see IsForest) that is also connected i.e.
+// exactly one node has no dependency
+func (g *Graph) IsTree() bool {
+	if !g.IsForest() {
+		return false
+	}
+
+	roots := 0
+	for n := range g.Top {
+		if len(g.distinctDependencies(n.ID())) == 0 {
+			roots++
+		}
+	}
+
+	return roots == 1
+}
+
+// distinctDependencies returns the ids of a node's distinct dependencies in the graph
+func (g *Graph) distinctDependencies(id int) map[int]bool {
+	deps := make(map[int]bool)
+	for _, d := range g.dependencyIDs(id) {
+		deps[d] = true
+	}
+	return deps
+}
@@ -1,5 +1,7 @@
 package fabric
 
+import "fmt"
+
 // Node is used to wrap data structure elements to become generic CDS Nodes
 type Node interface {
 	ID() int // returns node id
@@ -30,3 +32,147 @@ type CDS interface {
 	ListNodes() NodeList // a simple `return MyCDS.Nodes` will suffice here; once a NodeList has been created
 	ListEdges() EdgeList // a simple `return MyCDS.Edges` will suffice here; once an EdgesList has been created
 }
+
+// Composite is optionally implemented by CDS nodes that are themselves
+// containers of another data structure (e.g. a tree node holding a list)
+type Composite interface {
+	SubCDS() CDS
+}
+
+// FlatNode is a node of a sub-CDS inlined into a flattened CDS (see FlattenCDS)
+type FlatNode struct {
+	Node             // the node in its sub-CDS
+	Id        int    // the node's id in the flattened CDS
+	Namespace string // the path of ids of the composite nodes containing the node e.g. "3/7"
+}
+
+// ID returns the node's id in the flattened CDS
+func (n FlatNode) ID() int {
+	return n.Id
+}
+
+// FlatEdge is an edge of a sub-CDS inlined into a flattened CDS (see FlattenCDS)
+type FlatEdge struct {
+	Edge             // the edge in its sub-CDS
+	Id          int  // the edge's id in the flattened CDS
+	Source      Node // the edge's source node in the flattened CDS
+	Destination Node // the edge's destination node in the flattened CDS
+}
+
+// ID returns the edge's id in the flattened CDS
+func (e FlatEdge) ID() int {
+	return e.Id
+}
+
+// GetSource ...
+func (e FlatEdge) GetSource() Node {
+	return e.Source
+}
+
+// GetDestination ...
+func (e FlatEdge) GetDestination() Node {
+	return e.Destination
+}
+
+// flatCDS is a CDS created by FlattenCDS
+type flatCDS struct {
+	nodes NodeList
+	edges EdgeList
+}
+
+// GenNodeID ...
+func (c *flatCDS) GenNodeID() int {
+	id := 1
+	for _, n := range c.nodes {
+		if n.ID() >= id {
+			id = n.ID() + 1
+		}
+	}
+	return id
+}
+
+// GenEdgeID ...
+func (c *flatCDS) GenEdgeID() int {
+	id := 1
+	for _, e := range c.edges {
+		if e.ID() >= id {
+			id = e.ID() + 1
+		}
+	}
+	return id
+}
+
+// ListNodes ...
+func (c *flatCDS) ListNodes() NodeList {
+	return c.nodes
+}
+
+// ListEdges ...
+func (c *flatCDS) ListEdges() EdgeList {
+	return c.edges
+}
+
+// FlattenCDS returns a CDS with the nodes and edges of every sub-CDS of the
+// Composite nodes in a CDS (recursively) inlined, so that sections and
+// coverage can be computed over the whole nested structure. The nodes and
+// edges of the CDS itself keep their ids, while the nodes and edges of
+// sub-CDSs are given new ids (see FlatNode and FlatEdge). Composite nodes
+// remain in the flattened CDS, but are not connected to their sub-CDS nodes.
+func FlattenCDS(c CDS) CDS {
+	flat := &flatCDS{
+		nodes: append(NodeList{}, c.ListNodes()...),
+		edges: append(EdgeList{}, c.ListEdges()...),
+	}
+
+	nodeID := flat.GenNodeID()
+	edgeID := flat.GenEdgeID()
+
+	var inline func(NodeList, string)
+	inline = func(nodes NodeList, namespace string) {
+		for _, n := range nodes {
+			original := n
+			if f, ok := n.(FlatNode); ok {
+				original = f.Node
+			}
+			comp, ok := original.(Composite)
+			if !ok || comp.SubCDS() == nil {
+				continue
+			}
+			sub := comp.SubCDS()
+
+			ns := fmt.Sprint(original.ID())
+			if namespace != "" {
+				ns = namespace + "/" + ns
+			}
+
+			// sub-CDS node ids are only unique within the sub-CDS
+			inlined := make(map[int]Node)
+			var subNodes NodeList
+			for _, s := range sub.ListNodes() {
+				f := FlatNode{Node: s, Id: nodeID, Namespace: ns}
+				nodeID++
+				inlined[s.ID()] = f
+				subNodes = append(subNodes, f)
+				flat.nodes = append(flat.nodes, f)
+			}
+
+			for _, e := range sub.ListEdges() {
+				src, ok := inlined[e.GetSource().ID()]
+				if !ok {
+					continue
+				}
+				dst, ok := inlined[e.GetDestination().ID()]
+				if !ok {
+					continue
+				}
+				flat.edges = append(flat.edges, FlatEdge{Edge: e, Id: edgeID, Source: src, Destination: dst})
+				edgeID++
+			}
+
+			inline(subNodes, ns)
+		}
+	}
+	inline(c.ListNodes(), "")
+
+	return flat
+}
@@ -0,0 +1,147 @@
+package fabric
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChainNode is a node of a graph created by CollapseChains that stands for
+// a linear chain of nodes of the original graph
+type ChainNode struct {
+	Nodes     []int    // ids of the original nodes, from dependent to dependency
+	Type      NodeType // type of the original node if the chain has a single node (Unknown otherwise)
+	Priority  int      // highest priority of the original nodes
+	Signalers SignalingMap
+	Signals   SignalsMap
+}
+
+// ID returns the id of the first (dependent-most) original node in the chain
+func (c *ChainNode) ID() int {
+	return c.Nodes[0]
+}
+
+// GetType ...
+func (c *ChainNode) GetType() NodeType {
+	return c.Type
+}
+
+// GetPriority ...
+func (c *ChainNode) GetPriority() int {
+	return c.Priority
+}
+
+// ListProcedures ...
+func (c *ChainNode) ListProcedures() ProcedureList {
+	return ProcedureList{}
+}
+
+// UpdateSignaling ...
+func (c *ChainNode) UpdateSignaling(sm SignalingMap, s SignalsMap) {
+	c.Signalers = sm
+	c.Signals = s
+}
+
+// ListSignalers ...
+func (c *ChainNode) ListSignalers() SignalingMap {
+	return c.Signalers
+}
+
+// ListSignals ...
+func (c *ChainNode) ListSignals() SignalsMap {
+	return c.Signals
+}
+
+// Signal ...
+func (c *ChainNode) Signal(s NodeSignal) {
+	for _, ch := range c.Signalers {
+		ch <- s
+	}
+}
+
+// String labels the node with the number of nodes collapsed into it
+func (c *ChainNode) String() string {
+	return fmt.Sprintf("%d nodes", len(c.Nodes))
+}
+
+// CollapseChains returns a new graph in which every maximal linear chain of
+// nodes (a sequence in which every node but the last has only the next node
+// as a dependency, and every node but the first has only the previous node
+// as a dependent) is replaced by a single ChainNode, preserving the branching
+// structure of the graph (e.g. for rendering huge graphs). Each ChainNode
+// lists the ids of the original nodes it stands for; the original graph is
+// not modified.
+func (g *Graph) CollapseChains() *Graph {
+	keys := g.nodesByID()
+	ids := make([]int, 0, len(keys))
+	for id := range keys {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	// next links a node to its only dependency if it is that dependency's only dependent
+	next := make(map[int]int)
+	hasPrev := make(map[int]bool)
+	for _, id := range ids {
+		deps := g.distinctDependencies(id)
+		if len(deps) != 1 {
+			continue
+		}
+		for d := range deps {
+			if d != id && len(g.dependentIDs(d)) == 1 {
+				next[id] = d
+				hasPrev[d] = true
+			}
+		}
+	}
+
+	chainOf := make(map[int]*ChainNode)
+	var chains []*ChainNode
+	follow := func(start int) {
+		c := &ChainNode{
+			Type:      Unknown,
+			Priority:  keys[start].GetPriority(),
+			Signalers: make(SignalingMap),
+			Signals:   make(SignalsMap),
+		}
+		for id, ok := start, true; ok && chainOf[id] == nil; id, ok = next[id] {
+			c.Nodes = append(c.Nodes, id)
+			chainOf[id] = c
+			if p := keys[id].GetPriority(); p > c.Priority {
+				c.Priority = p
+			}
+		}
+		if len(c.Nodes) == 1 {
+			c.Type = keys[start].GetType()
+		}
+		chains = append(chains, c)
+	}
+
+	for _, id := range ids {
+		if !hasPrev[id] {
+			follow(id)
+		}
+	}
+	// whatever is left forms cycles of linked nodes
+	for _, id := range ids {
+		if chainOf[id] == nil {
+			follow(id)
+		}
+	}
+
+	collapsed := NewGraph()
+	if ds, ok := g.CDS(); ok {
+		collapsed.SetCDS(ds)
+	}
+	for _, c := range chains {
+		collapsed.AddRealNode(c)
+	}
+	for _, id := range ids {
+		for d := range g.distinctDependencies(id) {
+			if src, dst := chainOf[id], chainOf[d]; src != dst {
+				collapsed.addRealEdge(src.ID(), dst)
+			}
+		}
+	}
+
+	return collapsed
+}
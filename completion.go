@@ -0,0 +1,62 @@
+package fabric
+
+import (
+	"context"
+	"sync"
+)
+
+// CompletionTracker can be used to block until an entire graph has finished
+// execution. Nodes report completion through Done() (e.g. when they signal
+// Completed to their dependents); Wait() unblocks once every root boundary
+// node (the nodes no other node depends on, and therefore the last to
+// complete) has reported.
+type CompletionTracker struct {
+	mu      sync.Mutex
+	pending map[int]bool
+	done    chan struct{}
+}
+
+// CompletionGroup returns a CompletionTracker for the current root boundary
+// nodes of the graph
+func (g *Graph) CompletionGroup() *CompletionTracker {
+	c := &CompletionTracker{
+		pending: make(map[int]bool),
+		done:    make(chan struct{}),
+	}
+
+	for _, r := range g.Roots() {
+		c.pending[r.ID()] = true
+	}
+
+	if len(c.pending) == 0 {
+		close(c.done)
+	}
+
+	return c
+}
+
+// Done marks the node with the given id as completed
+func (c *CompletionTracker) Done(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.pending[id] {
+		return
+	}
+
+	delete(c.pending, id)
+	if len(c.pending) == 0 {
+		close(c.done)
+	}
+}
+
+// Wait blocks until every root boundary node has completed, or returns the
+// context's error if it is cancelled first
+func (c *CompletionTracker) Wait(ctx context.Context) error {
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,283 @@
+package fabric
+
+import (
+	"reflect"
+	"sort"
+)
+
+// sectioned is satisfied by any dependency graph node with a section
+// (e.g. UI nodes, or temporal nodes that define one)
+type sectioned interface {
+	GetSection() Section
+}
+
+// sectionsOf returns the sections of all nodes of the given type in the graph
+func (g *Graph) sectionsOf(t NodeType) []Section {
+	var sections []Section
+	for v := range g.Top {
+		if v.GetType() != t {
+			continue
+		}
+		if s, ok := v.(sectioned); ok && s.GetSection() != nil {
+			sections = append(sections, s.GetSection())
+		}
+	}
+	return sections
+}
+
+// CoverageGaps returns all CDS nodes and edges that are not covered
+// by the section of at least one UI node in the graph
+func (g *Graph) CoverageGaps() (NodeList, EdgeList) {
+	return g.coverageGaps(g.sectionsOf(UINode))
+}
+
+// CoveredBy checks whether the sections of only the nodes of a given type
+// cover all CDS nodes and all CDS edges (respectively). Nodes of the type
+// that do not have a GetSection() method are ignored.
+func (g *Graph) CoveredBy(t NodeType) (nodesCovered, edgesCovered bool) {
+	nodes, edges := g.coverageGaps(g.sectionsOf(t))
+	return len(nodes) == 0, len(edges) == 0
+}
+
+// coverageGaps returns all nodes and edges of the graph's CDS not covered by any of the sections
+func (g *Graph) coverageGaps(sections []Section) (NodeList, EdgeList) {
+	ds, ok := g.CDS()
+	if !ok {
+		return make(NodeList, 0), make(EdgeList, 0)
+	}
+
+	return gapsOf(ds, sections)
+}
+
+// gapsOf returns all nodes and edges of a CDS not covered by any of the sections
+func gapsOf(ds CDS, sections []Section) (NodeList, EdgeList) {
+	nodes := make(NodeList, 0)
+	edges := make(EdgeList, 0)
+
+FIRST:
+	// for every node in the CDS
+	for _, v := range ds.ListNodes() {
+		// check that at least one UI contains it
+		for _, s := range sections {
+			if ContainsNode(*s.ListNodes(), v) {
+				continue FIRST
+			}
+		}
+
+		// if CDS node is checked in every UI and does not show up
+		nodes = append(nodes, v)
+	}
+
+SECOND:
+	// for every edge in the CDS
+	for _, v := range ds.ListEdges() {
+		// check that at least one UI contains it
+		for _, s := range sections {
+			if ContainsEdge(*s.ListEdges(), v) {
+				continue SECOND
+			}
+		}
+
+		// if CDS edge is checked in every UI and does not show up
+		edges = append(edges, v)
+	}
+
+	return nodes, edges
+}
+
+// Coverage is a report of how well the UI nodes of a graph cover its CDS
+type Coverage struct {
+	Covered        bool
+	UncoveredNodes NodeList
+	UncoveredEdges EdgeList
+}
+
+// CoverageReport returns whether the CDS is covered along with all
+// uncovered CDS nodes and edges
+func (g *Graph) CoverageReport() Coverage {
+	nodes, edges := g.CoverageGaps()
+
+	return Coverage{
+		Covered:        g.DS != nil && len(nodes) == 0 && len(edges) == 0,
+		UncoveredNodes: nodes,
+		UncoveredEdges: edges,
+	}
+}
+
+// CDSObserver can be satisfied by UI nodes that want to be notified when
+// CDS nodes in their section change (see NotifyCDSChange)
+type CDSObserver interface {
+	CDSChanged(changedNodeIDs []int)
+}
+
+// NotifyCDSChange returns the sorted ids of all UI nodes (real or virtual)
+// whose section contains any of the changed CDS nodes; those that satisfy
+// CDSObserver are also notified with the ids of the changed nodes in their
+// section.
+func (g *Graph) NotifyCDSChange(changedNodeIDs []int) []int {
+	changed := make(map[int]bool)
+	for _, id := range changedNodeIDs {
+		changed[id] = true
+	}
+
+	var uis []DGNode
+	for n := range g.Top {
+		uis = append(uis, n)
+	}
+	sortNodes(uis)
+
+	ids := make([]int, 0)
+	for _, n := range uis {
+		u, ok := n.(UI)
+		if !ok || u.GetSection() == nil {
+			continue
+		}
+
+		var affected []int
+		for _, v := range *u.GetSection().ListNodes() {
+			if changed[v.ID()] {
+				affected = append(affected, v.ID())
+			}
+		}
+		if len(affected) == 0 {
+			continue
+		}
+
+		ids = append(ids, n.ID())
+		if o, ok := n.(CDSObserver); ok {
+			o.CDSChanged(affected)
+		}
+	}
+
+	return ids
+}
+
+// UIsForEdgeChange returns the sorted ids of all UI nodes (real or virtual)
+// whose section contains the CDS edge from the src CDS node to the dst CDS
+// node, i.e. the UI nodes affected by a change to that edge (e.g. a tree
+// re-parenting) rather than to the value of a node (see NotifyCDSChange).
+func (g *Graph) UIsForEdgeChange(src, dst int) []int {
+	ids := make([]int, 0)
+
+	for n := range g.Top {
+		u, ok := n.(UI)
+		if !ok || u.GetSection() == nil {
+			continue
+		}
+
+		for _, e := range *u.GetSection().ListEdges() {
+			if e.GetSource().ID() == src && e.GetDestination().ID() == dst {
+				ids = append(ids, n.ID())
+				break
+			}
+		}
+	}
+
+	sort.Ints(ids)
+	return ids
+}
+
+// SharedSectionNodes returns every CDS node id that is in the section of
+// more than one UI node, mapped to the sorted ids of those UI nodes; i.e. all
+// violations of the assumption that each region of the CDS is owned by a
+// single UI (thread).
+func (g *Graph) SharedSectionNodes() map[int][]int {
+	claims := make(map[int][]int)
+	for n := range g.Top {
+		u, ok := n.(UI)
+		if !ok || u.GetSection() == nil {
+			continue
+		}
+
+		seen := make(map[int]bool)
+		for _, v := range *u.GetSection().ListNodes() {
+			if !seen[v.ID()] {
+				seen[v.ID()] = true
+				claims[v.ID()] = append(claims[v.ID()], n.ID())
+			}
+		}
+	}
+
+	shared := make(map[int][]int)
+	for id, uis := range claims {
+		if len(uis) > 1 {
+			sort.Ints(uis)
+			shared[id] = uis
+		}
+	}
+
+	return shared
+}
+
+// CDSNamer can be satisfied by UI nodes in graphs that contain the UIs of
+// several CDSs, in order to specify the (name of the) CDS their section
+// belongs to (see CoveredMulti)
+type CDSNamer interface {
+	CDSName() string
+}
+
+// CoveredMulti checks, for every named CDS, whether all of its nodes and
+// edges are covered by the sections of the UI nodes that belong to it (i.e.
+// whose CDSName() returns its name). UI nodes that do not satisfy CDSNamer
+// are not counted towards any of the CDSs.
+func (g *Graph) CoveredMulti(cdss map[string]CDS) map[string]bool {
+	sections := make(map[string][]Section)
+	for n := range g.Top {
+		u, ok := n.(UI)
+		if !ok || u.GetSection() == nil {
+			continue
+		}
+		if c, ok := n.(CDSNamer); ok {
+			sections[c.CDSName()] = append(sections[c.CDSName()], u.GetSection())
+		}
+	}
+
+	covered := make(map[string]bool)
+	for name, ds := range cdss {
+		nodes, edges := gapsOf(ds, sections[name])
+		covered[name] = len(nodes) == 0 && len(edges) == 0
+	}
+
+	return covered
+}
+
+// WouldRemainUnique checks whether the graph would still satisfy
+// TotalityUnique if a new UI node were added, without modifying the graph
+func (g *Graph) WouldRemainUnique(newUI UI) bool {
+	if !g.TotalityUnique() {
+		return false
+	}
+	if newUI.GetType() != UINode {
+		return true
+	}
+
+	for n := range g.Top {
+		if n.GetType() == UINode && reflect.DeepEqual(n, DGNode(newUI)) {
+			return false
+		}
+	}
+	return true
+}
+
+// WouldRemainCovered checks whether the CDS would still be covered (see
+// Covered) if a UI node were removed, without modifying the graph; it
+// returns false if there is no CDS bound to the graph.
+func (g *Graph) WouldRemainCovered(removeUIID int) bool {
+	ds, ok := g.CDS()
+	if !ok {
+		return false
+	}
+
+	var sections []Section
+	for n := range g.Top {
+		if n.ID() == removeUIID || n.GetType() != UINode {
+			continue
+		}
+		if s, ok := n.(sectioned); ok && s.GetSection() != nil {
+			sections = append(sections, s.GetSection())
+		}
+	}
+
+	nodes, edges := gapsOf(ds, sections)
+	return len(nodes) == 0 && len(edges) == 0
+}
@@ -0,0 +1,131 @@
+package fabric
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// CyclePolicy defines how a graph handles the insertion of an edge that
+// would create a cycle
+type CyclePolicy int
+
+const (
+	// Allow inserts the edge anyway (e.g. for intentional feedback loops)
+	Allow CyclePolicy = iota
+	// Reject returns an error and does not insert the edge
+	Reject
+	// Break inserts the edge and then removes the lowest weight edge (see
+	// SetEdgeWeight) of every cycle the edge created, preferring the new edge
+	// on ties, to restore acyclicity
+	Break
+)
+
+// AddRealEdgeChecked is the same as AddRealEdge but returns an error if the
+// source node does not exist, if the edge would exceed the graph's MaxDepth,
+// if the edge is rejected by the graph's CyclePolicy, or (in Strict mode)
+// if a contract assertion fails. Adding an
+// edge that already exists does nothing.
+// NOTE: there is no cached cycle state; whether an edge would create a
+// cycle is determined at insertion time by searching for a path from the
+// destination back to the source, so modifications made directly to Top
+// are always taken into account.
+func (g *Graph) AddRealEdgeChecked(source int, dest DGNode) error {
+	if g.Strict {
+		if err := g.strictEdge(source, dest); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := g.GetNode(source); !ok {
+		return fmt.Errorf("Source node %d: %w", source, ErrNodeNotFound)
+	}
+
+	if g.hasEdge(source, dest.ID()) {
+		return nil
+	}
+
+	if g.MaxDepth > 0 {
+		if l := g.chainThrough(source, dest.ID()); l > g.MaxDepth {
+			return fmt.Errorf("Edge from %d to %d would create a dependency chain of length %d (maximum depth is %d)", source, dest.ID(), l, g.MaxDepth)
+		}
+	}
+
+	if g.CyclePolicy == Reject && g.WouldCycle(source, dest.ID()) {
+		return fmt.Errorf("Edge from %d to %d: %w", source, dest.ID(), ErrCycle)
+	}
+
+	g.addRealEdge(source, dest)
+
+	if g.CyclePolicy == Break {
+		g.breakCycles(source, dest.ID())
+	}
+
+	return nil
+}
+
+// WouldCycle checks whether adding an edge from source to dest would create
+// a cycle, by only searching for a path from dest back to source (see
+// Reachable) rather than checking the whole graph (see CycleDetect)
+func (g *Graph) WouldCycle(source, dest int) bool {
+	return source == dest || g.Reachable(dest, source)
+}
+
+// breakCycles removes the lowest weight edge of every cycle through the
+// (new) edge from source to dest, until the edge is no longer on a cycle
+func (g *Graph) breakCycles(source, dest int) {
+	for g.hasEdge(source, dest) {
+		if source == dest {
+			g.removeEdge(source, dest)
+			return
+		}
+
+		path, _, err := g.ShortestWeightedPath(dest, source)
+		if err != nil || len(path) < 2 {
+			return
+		}
+
+		weakest := [2]int{source, dest}
+		for i := 0; i < len(path)-1; i++ {
+			e := [2]int{path[i].ID(), path[i+1].ID()}
+			if g.EdgeWeight(e[0], e[1]) < g.EdgeWeight(weakest[0], weakest[1]) {
+				weakest = e
+			}
+		}
+
+		g.removeEdge(weakest[0], weakest[1])
+	}
+}
+
+// removeEdge removes the edge between two nodes by id
+func (g *Graph) removeEdge(source, dest int) {
+	d, ok := g.GetNode(dest)
+	if !ok {
+		return
+	}
+	g.RemoveRealEdge(source, d)
+}
+
+// CycleDetectContext is the same as CycleDetect but stops and returns the
+// context's error if the context is cancelled or its deadline passes before
+// the check completes. The check is iterative (see TopoStream) so memory use
+// is bounded by the size of the graph rather than the depth of its chains.
+func (g *Graph) CycleDetectContext(ctx context.Context) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	var cancelled error
+	err := g.topoWalk(func(DGNode) bool {
+		cancelled = ctx.Err()
+		return cancelled == nil
+	})
+	if cancelled != nil {
+		return false, cancelled
+	}
+	if errors.Is(err, ErrCycle) {
+		return true, nil
+	}
+
+	return false, err
+}
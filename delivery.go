@@ -0,0 +1,146 @@
+package fabric
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DeliveryMode defines how Deliver sends signals across an edge
+type DeliveryMode int
+
+const (
+	// Blocking sends block until the signal is received (the default)
+	Blocking DeliveryMode = iota
+	// AtMostOnce uses a single non-blocking send; the signal is dropped if
+	// it is not immediately received (e.g. progress heartbeats)
+	AtMostOnce
+	// AtLeastOnce retries a blocked send (see Graph.DeliveryRetries and
+	// Graph.DeliveryTimeout) so that the signal is not lost to a slow receiver
+	// (e.g. aborts); the signal is dropped if every attempt times out
+	AtLeastOnce
+)
+
+const (
+	// defaultDeliveryRetries is the number of attempts an AtLeastOnce
+	// delivery makes if Graph.DeliveryRetries is not set
+	defaultDeliveryRetries = 3
+	// defaultDeliveryTimeout is how long each attempt of an AtLeastOnce
+	// delivery waits if Graph.DeliveryTimeout is not set
+	defaultDeliveryTimeout = 100 * time.Millisecond
+)
+
+// SetDeliveryMode sets how signals are delivered across an existing edge
+// (see Deliver)
+func (g *Graph) SetDeliveryMode(source, dest int, mode DeliveryMode) error {
+	m, err := g.meta(source, dest)
+	if err != nil {
+		return err
+	}
+
+	m.delivery = mode
+	return nil
+}
+
+// DeliveryMode returns the delivery mode of an edge (Blocking if it has not been set)
+func (g *Graph) DeliveryMode(source, dest int) DeliveryMode {
+	if m, ok := g.edges[edgeKey(source, dest)]; ok {
+		return m.delivery
+	}
+	return Blocking
+}
+
+// DroppedSignals returns the number of signals Deliver (or the graph's
+// Transport, see RebuildSignaling) has dropped on an edge
+func (g *Graph) DroppedSignals(source, dest int) int {
+	if m, ok := g.edges[edgeKey(source, dest)]; ok {
+		return int(atomic.LoadInt64(&m.dropped))
+	}
+	return 0
+}
+
+// Deliver sends a signal across the edge from source to dest i.e. from the
+// dest node to its dependent source node, according to the edge's delivery
+// mode (or coalesced, see CoalesceSignals). It will return an error if the edge does not exist or if the signal
+// was dropped (which is recorded, see DroppedSignals).
+func (g *Graph) Deliver(source, dest int, s NodeSignal) error {
+	m, err := g.meta(source, dest)
+	if err != nil {
+		return err
+	}
+
+	d, _ := g.GetNode(dest)
+	c, ok := d.ListSignalers()[source]
+	if !ok {
+		return fmt.Errorf("Node %d has no signaling channel to node %d", dest, source)
+	}
+
+	if g.CoalesceSignals && cap(c) > 0 {
+		SendCoalesced(c, s)
+		return nil
+	}
+
+	switch m.delivery {
+	case AtMostOnce:
+		select {
+		case c <- s:
+			return nil
+		default:
+		}
+	case AtLeastOnce:
+		retries, timeout := g.DeliveryRetries, g.DeliveryTimeout
+		if retries <= 0 {
+			retries = defaultDeliveryRetries
+		}
+		if timeout <= 0 {
+			timeout = defaultDeliveryTimeout
+		}
+
+		for i := 0; i < retries; i++ {
+			select {
+			case c <- s:
+				return nil
+			case <-time.After(timeout):
+			}
+		}
+	default:
+		c <- s
+		return nil
+	}
+
+	atomic.AddInt64(&m.dropped, 1)
+	return fmt.Errorf("Signal from node %d to node %d was dropped", dest, source)
+}
+
+// newSignalChan creates a signaling channel between two graph nodes
+func (g *Graph) newSignalChan() chan NodeSignal {
+	if g.CoalesceSignals {
+		return make(chan NodeSignal, 1)
+	}
+	return make(chan NodeSignal)
+}
+
+// SendCoalesced sends a signal over a buffered channel without blocking,
+// replacing any signal in the buffer the receiver has not consumed yet (i.e.
+// the channel is a conflated queue of depth 1, see CoalesceSignals); signals
+// over unbuffered channels are sent normally.
+func SendCoalesced(c chan NodeSignal, s NodeSignal) {
+	if cap(c) == 0 {
+		c <- s
+		return
+	}
+
+	for {
+		select {
+		case c <- s:
+			return
+		default:
+		}
+
+		// discard the stale signal (unless the receiver just consumed it)
+		select {
+		case <-c:
+		default:
+		}
+	}
+}
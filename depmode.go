@@ -0,0 +1,102 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// DepMode defines when a node's dependencies are satisfied
+type DepMode int
+
+const (
+	// All dependencies must complete before the node can start
+	All DepMode = iota
+	// Any one dependency completing allows the node to start (e.g. the fastest of several producers)
+	Any
+)
+
+// SetDependencyMode sets the dependency mode of a node; nodes use All by
+// default. The mode is honored by Scheduler, ReadyNodes, Run and Await.
+func (g *Graph) SetDependencyMode(id int, mode DepMode) {
+	if mode == All {
+		delete(g.depModes, id)
+		return
+	}
+
+	if g.depModes == nil {
+		g.depModes = make(map[int]DepMode)
+	}
+	g.depModes[id] = mode
+}
+
+// DependencyMode returns the dependency mode of a node
+func (g *Graph) DependencyMode(id int) DepMode {
+	return g.depModes[id]
+}
+
+// Await waits on a node's incoming signaling channels according to its
+// dependency mode: with All it is the same as AwaitDependencies, while with
+// Any it returns as soon as one dependency signals Completed (the signals
+// received until then are returned, along with the sorted ids of the
+// dependencies whose channel was closed). If every dependency instead sends
+// another terminal signal or closes its channel, ErrDependenciesFailed is
+// returned. It will return the context's error if the context is done first.
+func (g *Graph) Await(ctx context.Context, id int) (map[int]NodeSignal, []int, error) {
+	n, ok := g.GetNode(id)
+	if !ok {
+		return nil, nil, fmt.Errorf("Node %d: %w", id, ErrNodeNotFound)
+	}
+
+	if g.DependencyMode(id) == All {
+		return AwaitDependencies(ctx, n.ListSignals())
+	}
+
+	signals := make(map[int]NodeSignal)
+	removed := make([]int, 0)
+
+	sm := n.ListSignals()
+	ids := make([]int, 0, len(sm))
+	cases := []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}}
+	for dep := range sm {
+		ids = append(ids, dep)
+	}
+	sort.Ints(ids)
+	for _, dep := range ids {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sm[dep])})
+	}
+
+	for open := len(ids); open > 0; {
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return signals, removed, ctx.Err()
+		}
+
+		dep := ids[chosen-1]
+		if !ok {
+			// stop selecting on the closed channel
+			cases[chosen].Chan = reflect.ValueOf((<-chan NodeSignal)(nil))
+			removed = append(removed, dep)
+			sort.Ints(removed)
+			open--
+			continue
+		}
+
+		s := v.Interface().(NodeSignal)
+		signals[dep] = s
+		if s.Effective() == Completed {
+			return signals, removed, nil
+		}
+		if s.IsTerminal() {
+			// the dependency will not complete anymore
+			cases[chosen].Chan = reflect.ValueOf((<-chan NodeSignal)(nil))
+			open--
+		}
+	}
+
+	if len(ids) == 0 {
+		return signals, removed, nil
+	}
+	return signals, removed, fmt.Errorf("Node %d: %w", id, ErrDependenciesFailed)
+}
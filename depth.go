@@ -0,0 +1,85 @@
+package fabric
+
+// chainFrame is a node on the current chain of chainLength's walk
+type chainFrame struct {
+	id      int
+	next    []int
+	i       int  // index of the next node to follow
+	longest int  // number of edges in the longest chain found so far
+	cyclic  bool // whether an edge back to the current chain was ignored
+}
+
+// chainLength returns the number of edges in the longest chain from a node
+// following next (i.e. towards dependencies or towards dependents); edges
+// back to a node already on the current chain are ignored. The walk is
+// iterative so that deep graphs can not overflow the stack, and only chain
+// lengths that did not depend on an ignored edge are reused.
+func (g *Graph) chainLength(id int, next func(int) []int) int {
+	memo := make(map[int]int)
+	path := map[int]bool{id: true}
+	stack := []*chainFrame{{id: id, next: next(id)}}
+
+	for {
+		f := stack[len(stack)-1]
+		if f.i < len(f.next) {
+			n := f.next[f.i]
+			f.i++
+
+			if path[n] {
+				f.cyclic = true
+				continue
+			}
+			if l, ok := memo[n]; ok {
+				if l+1 > f.longest {
+					f.longest = l + 1
+				}
+				continue
+			}
+
+			path[n] = true
+			stack = append(stack, &chainFrame{id: n, next: next(n)})
+			continue
+		}
+
+		// every chain from f has been followed
+		delete(path, f.id)
+		if !f.cyclic {
+			memo[f.id] = f.longest
+		}
+
+		stack = stack[:len(stack)-1]
+		if len(stack) == 0 {
+			return f.longest
+		}
+
+		parent := stack[len(stack)-1]
+		if f.longest+1 > parent.longest {
+			parent.longest = f.longest + 1
+		}
+		parent.cyclic = parent.cyclic || f.cyclic
+	}
+}
+
+// dependencyIDs returns the ids of the (existing) dependencies of a node
+func (g *Graph) dependencyIDs(id int) []int {
+	var ids []int
+
+	n, ok := g.GetNode(id)
+	if !ok {
+		return ids
+	}
+	for _, d := range g.Top[n] {
+		if _, ok := g.GetNode(d.ID()); ok {
+			ids = append(ids, d.ID())
+		}
+	}
+	return ids
+}
+
+// chainThrough returns the number of edges in the longest dependency chain
+// that would contain a new edge from source to dest
+func (g *Graph) chainThrough(source, dest int) int {
+	up := g.chainLength(source, g.dependentIDs)
+	down := g.chainLength(dest, g.dependencyIDs)
+	return up + 1 + down
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -33,12 +35,65 @@ const (
 	Help
 )
 
+// SignalValue is the interface a signal value must satisfy; the built-in Signal
+// type is the default implementation, but domain-specific signal types can be
+// used as well (see NodeSignal.Custom).
+type SignalValue interface {
+	IsTerminal() bool // specifies whether the signal marks the end of an access procedure's execution
+}
+
+// IsTerminal returns true for the Completed, Aborted and PartialAbort signals
+func (s Signal) IsTerminal() bool {
+	switch s {
+	case Completed, Aborted, PartialAbort:
+		return true
+	}
+
+	return false
+}
+
 // NodeSignal carries all the information a dependent node will need in order to know what
 // action a dependent node has just taken.
 type NodeSignal struct {
 	AccessType int // should be equivalent to the ID() method return value for the Access Type
 	Value      Signal
 	Space      UI
+	Custom     SignalValue // optional domain-specific signal value; takes precedence over Value when set
+	Payload    interface{} // optional data sent along with the signal (e.g. the reason for an abort)
+	Ack        chan int    // optional channel receivers acknowledge the signal on (see SignalAwaitAck)
+}
+
+// AbortSignal creates an Aborted signal carrying the reason for the abort as its payload
+func AbortSignal(accessType int, reason error) NodeSignal {
+	return NodeSignal{
+		AccessType: accessType,
+		Value:      Aborted,
+		Payload:    reason,
+	}
+}
+
+// Reason returns the signal's payload if it is an error (e.g. the reason
+// for an abort) and nil otherwise
+func (s NodeSignal) Reason() error {
+	if err, ok := s.Payload.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// Effective returns the Custom signal value if one is set, otherwise the built-in Value
+func (s NodeSignal) Effective() SignalValue {
+	if s.Custom != nil {
+		return s.Custom
+	}
+
+	return s.Value
+}
+
+// IsTerminal reports whether the effective signal value is terminal
+func (s NodeSignal) IsTerminal() bool {
+	return s.Effective().IsTerminal()
 }
 
 // NodeType defines the possible values for types of dependency graph nodes
@@ -83,16 +138,86 @@ type DGNode interface {
 // Graph can be either UI DDAG, Temporal DAG or VDG
 type Graph struct {
 	DS  CDS
-	Top map[DGNode][]DGNode
+	Top map[DGNode][]DGNode // node to its dependencies; call Reindex after modifying it directly
 	VDG []*VDG
+
+	// Strict, when enabled, runs assertions on the contracts graph nodes must
+	// satisfy (e.g. unique ids, UI nodes having a section) at mutation time
+	// and returns detailed errors for any violations; mutations that cannot
+	// return an error (AddRealEdge) panic instead.
+	Strict bool
+
+	// CyclePolicy defines how edges that would create a cycle are handled
+	// when they are added (Allow by default)
+	CyclePolicy CyclePolicy
+
+	// MaxDepth, when > 0, is the maximum number of edges in any dependency
+	// chain; edges that would create a longer chain are rejected when added
+	MaxDepth int
+
+	// RecoverPanics, when enabled, makes Run recover a panic in a node's
+	// work and treat it as an Aborted signal (with the panic value as its
+	// payload) instead of crashing the whole run
+	RecoverPanics bool
+
+	// CoalesceSignals, when enabled, gives signaling channels created by the
+	// graph a buffer of one signal which Deliver (and SendCoalesced) replaces
+	// if the receiver has not consumed it yet, so that dependents only see
+	// the latest signal of a rapidly changing node
+	CoalesceSignals bool
+
+	// DeliveryRetries is the number of attempts an AtLeastOnce delivery
+	// makes (3 if not set), and DeliveryTimeout is how long each attempt
+	// waits for the receiver (100ms if not set); see Deliver
+	DeliveryRetries int
+	DeliveryTimeout time.Duration
+
+	// Transport is used for delivering signals between nodes; if nil
+	// signals are sent directly over in-process channels.
+	Transport Transport
+
+	ids        map[int]DGNode // id index over the keys of Top
+	dependents map[int][]int  // reverse adjacency index: node id to dependent ids
+
+	edges map[[2]int]*edgeMeta // metadata of edges (source id, destination id)
+
+	timeouts map[int]time.Duration // per-node execution timeouts
+
+	depModes map[int]DepMode // per-node dependency modes (All by default)
+
+	forwarded map[chan NodeSignal]Transport // signaling channels forwarded through a Transport (see RebuildSignaling)
+
+	log *signalLog // latest reported signal state of each node
+
+	rng   *rand.Rand // seeded random number generator for GenID (see Seed)
+	rngMu sync.Mutex // guards rng, which is not safe for concurrent use
+
+	revision uint64   // incremented on every topology mutation
+	changes  []change // topology mutations by revision
 }
 
-// NewGraph creates a new empty graph
-func NewGraph() *Graph {
-	return &Graph{
+// NewGraph creates a new empty graph, optionally bound to a CDS
+func NewGraph(cds ...CDS) *Graph {
+	g := &Graph{
 		Top: make(map[DGNode][]DGNode),
 		VDG: make([]*VDG, 0),
 	}
+
+	if len(cds) > 0 {
+		g.DS = cds[0]
+	}
+
+	return g
+}
+
+// SetCDS binds (or rebinds) the graph to a CDS
+func (g *Graph) SetCDS(c CDS) {
+	g.DS = c
+}
+
+// CDS returns the CDS the graph is bound to, and false if it is not bound to one
+func (g *Graph) CDS() (CDS, bool) {
+	return g.DS, g.DS != nil
 }
 
 func SingleUIGraph(cds CDS) (*Graph, error) {
@@ -137,10 +262,39 @@ func (g *Graph) RemoveVDG(v *VDG) {
 	}
 }
 
+// NewSeededGraph is the same as NewGraph but GenID (and VDG.GenID for the
+// graph's VDGs) draws ids from a random number generator seeded once with
+// the given seed, so that id sequences are reproducible (see Seed)
+func NewSeededGraph(seed int64, cds ...CDS) *Graph {
+	g := NewGraph(cds...)
+	g.Seed(seed)
+	return g
+}
+
+// Seed makes GenID draw ids from a random number generator seeded once with
+// the given seed, instead of reseeding from the current time on every call
+func (g *Graph) Seed(seed int64) {
+	g.rngMu.Lock()
+	defer g.rngMu.Unlock()
+	g.rng = rand.New(rand.NewSource(seed))
+}
+
+// randInt returns a random non-negative int from the graph's seeded random
+// number generator, or from a newly time-seeded one if the graph is not seeded
+func (g *Graph) randInt() int {
+	g.rngMu.Lock()
+	defer g.rngMu.Unlock()
+
+	if g.rng != nil {
+		return g.rng.Int()
+	}
+	rand.Seed(time.Now().UnixNano())
+	return rand.Int()
+}
+
 // GenID ...
 func (g *Graph) GenID() int {
-	rand.Seed(time.Now().UnixNano())
-	id := rand.Int()
+	id := g.randInt()
 	for n := range g.Top {
 		if n.ID() == id {
 			id = g.GenID()
@@ -149,6 +303,24 @@ func (g *Graph) GenID() int {
 	return id
 }
 
+// NewNode allocates a new node ID (see GenID), creates a node with it using
+// the factory, and adds the node to the graph (see AddRealNode); it will
+// return an error if the factory does not use the allocated ID.
+func (g *Graph) NewNode(factory func(id int) DGNode) (DGNode, error) {
+	var newNode DGNode
+
+	id := g.GenID()
+	node := factory(id)
+	if node == nil {
+		return newNode, fmt.Errorf("Node factory returned no node for ID %d", id)
+	}
+	if node.ID() != id {
+		return newNode, fmt.Errorf("Node factory returned node with ID %d instead of allocated ID %d", node.ID(), id)
+	}
+
+	return g.AddRealNode(node)
+}
+
 // IsLeafBoundary ...
 func (g *Graph) IsLeafBoundary(n DGNode) bool {
 	if len(g.Dependencies(n)) == 0 {
@@ -168,27 +340,75 @@ func (g *Graph) IsRootBoundary(n DGNode) bool {
 }
 
 // SignalsAndSignalers will udpate the SignalingMaps and SignalsMaps for all DGNodes in the graph
+// If the graph has a Transport, signals sent on a SignalingMap channel are
+// delivered through the transport and SignalsMaps receive from the transport
+// (signals the transport fails to send are counted as dropped, see
+// DroppedSignals).
+// Channels that already exist between a node and its dependents are preserved
+// (see RebuildSignaling).
 func (g *Graph) SignalsAndSignalers() {
+	g.RebuildSignaling(false)
+}
 
-	// for all nodes in the graph
-	for n, l := range g.Top {
-		// create its SignalersMap
+// RebuildSignaling will update the SignalingMaps and SignalsMaps for all DGNodes
+// in the graph. If force is false, existing channels between a node and its
+// dependents are preserved (so any in-flight signals and custom buffer sizes
+// are kept) and only missing channels are created; if force is true, every
+// channel is recreated from scratch. When the graph has a Transport, every
+// channel is forwarded through it; channels forwarded through a previous
+// Transport are recreated, and replaced channels stop being forwarded (they
+// are closed).
+func (g *Graph) RebuildSignaling(force bool) {
+	signalers := make(map[int]SignalingMap)
+
+	// for all nodes in the graph create its SignalersMap
+	for n := range g.Top {
+		existing := n.ListSignalers()
 		sm := make(SignalingMap)
 		deps := g.Dependents(n)
 		for _, d := range deps {
-			c := make(chan NodeSignal)
+			c, ok := existing[d.ID()]
+			t, forwarded := g.forwarded[c]
+			if !ok || c == nil || force || (forwarded && t != g.Transport) {
+				c = g.newSignalChan()
+				forwarded = false
+			}
 			sm[d.ID()] = c
+
+			if g.Transport != nil && !forwarded {
+				if g.forwarded == nil {
+					g.forwarded = make(map[chan NodeSignal]Transport)
+				}
+				g.forwarded[c] = g.Transport
+
+				m, _ := g.meta(d.ID(), n.ID())
+				go forward(g.Transport, n.ID(), d.ID(), c, m)
+			}
 		}
 
-		// create its SignalsMap
+		// stop forwarding replaced channels
+		for id, c := range existing {
+			if _, forwarded := g.forwarded[c]; forwarded && sm[id] != c {
+				delete(g.forwarded, c)
+				closeSignal(c)
+			}
+		}
+
+		signalers[n.ID()] = sm
+	}
+
+	// for all nodes in the graph create its SignalsMap
+	for n, l := range g.Top {
 		s := make(SignalsMap)
 		for _, dep := range l {
-			channels := dep.ListSignalers()
-			ch := channels[dep.ID()]
-			s[dep.ID()] = ch
+			if g.Transport != nil {
+				s[dep.ID()] = g.Transport.Recv(dep.ID(), n.ID())
+				continue
+			}
+			s[dep.ID()] = signalers[dep.ID()][n.ID()]
 		}
 
-		n.UpdateSignaling(sm, s)
+		n.UpdateSignaling(signalers[n.ID()], s)
 	}
 }
 
@@ -225,14 +445,22 @@ func (g *Graph) TotalBlock(nodeID int, handler BasicSignalHandler) bool {
 // to intialize the graph.
 func (g *Graph) AddRealNode(node DGNode) (DGNode, error) {
 	var newNode DGNode
+	if g.Strict {
+		if err := g.strictNode(node); err != nil {
+			return newNode, err
+		}
+	}
+
 	if !reflect.ValueOf(node).Type().Comparable() {
 		return newNode, fmt.Errorf("Node type is not comparable and cannot be used in the graph topology. \n Try removing any slices, maps, and functions from struct definition.")
 	}
 
 	if _, ok := g.Top[node]; !ok {
 		g.Top[node] = []DGNode{}
+		g.indexNode(node)
+		g.recordNode(node.ID(), false)
 	} else {
-		return newNode, fmt.Errorf("Node already exists in Dependency Graph.")
+		return newNode, fmt.Errorf("Node %d: %w", node.ID(), ErrNodeExists)
 	}
 
 	for n := range g.Top {
@@ -243,29 +471,45 @@ func (g *Graph) AddRealNode(node DGNode) (DGNode, error) {
 	return newNode, nil
 }
 
-// AddRealEdge will create an edge and an appropriate signaling channel between nodes
+// AddRealEdge will create an edge and an appropriate signaling channel between nodes.
+// Edges rejected by the graph's CyclePolicy are dropped (use AddRealEdgeChecked
+// to get the error); in Strict mode any error will panic.
 func (g *Graph) AddRealEdge(source int, dest DGNode) {
+	if err := g.AddRealEdgeChecked(source, dest); err != nil && g.Strict {
+		panic(err)
+	}
+}
 
+// addRealEdge creates an edge and its signaling channel without any checks
+func (g *Graph) addRealEdge(source int, dest DGNode) {
 	for i, k := range g.Top {
 		if i.ID() == source {
 			if !contains(k, dest) {
 				k = append(k, dest)
 				g.Top[i] = k
+				g.indexEdge(i.ID(), dest.ID())
+				g.recordEdge(i.ID(), dest.ID(), false)
 
 				// update SignalingMap for destination
+				c := g.newSignalChan()
 				depSig := dest.ListSignalers()
 				depS := dest.ListSignals()
-				depSig[i.ID()] = make(chan NodeSignal)
+				depSig[i.ID()] = c
 				dest.UpdateSignaling(depSig, depS)
 
 				// update SignalsMap for source
 				signals := i.ListSignals()
 				signalers := i.ListSignalers()
-				for j, v := range dest.ListSignalers() {
-					if j == i.ID() {
-						signals[dest.ID()] = v
-						break
+				signals[dest.ID()] = c
+				if g.Transport != nil {
+					if g.forwarded == nil {
+						g.forwarded = make(map[chan NodeSignal]Transport)
 					}
+					g.forwarded[c] = g.Transport
+
+					m, _ := g.meta(i.ID(), dest.ID())
+					go forward(g.Transport, dest.ID(), i.ID(), c, m)
+					signals[dest.ID()] = g.Transport.Recv(dest.ID(), i.ID())
 				}
 				i.UpdateSignaling(signalers, signals)
 			}
@@ -273,6 +517,72 @@ func (g *Graph) AddRealEdge(source int, dest DGNode) {
 	}
 }
 
+// RemoveRealEdge will remove an edge and the signaling channel between nodes
+func (g *Graph) RemoveRealEdge(source int, dest DGNode) {
+	i, ok := g.GetNode(source)
+	if !ok {
+		return
+	}
+
+	k := g.Top[i]
+	for j, v := range k {
+		if v.ID() == dest.ID() {
+			k = append(k[:j], k[j+1:]...)
+			g.Top[i] = k
+			g.unindexEdge(i.ID(), dest.ID())
+			g.recordEdge(i.ID(), dest.ID(), true)
+			delete(g.edges, edgeKey(i.ID(), dest.ID()))
+
+			// update SignalingMap for destination (stopping the forwarding of
+			// its channel through the Transport)
+			depSig := dest.ListSignalers()
+			if c, ok := depSig[i.ID()]; ok {
+				if _, forwarded := g.forwarded[c]; forwarded {
+					delete(g.forwarded, c)
+					closeSignal(c)
+				}
+			}
+			delete(depSig, i.ID())
+			dest.UpdateSignaling(depSig, dest.ListSignals())
+
+			// update SignalsMap for source
+			signals := i.ListSignals()
+			delete(signals, dest.ID())
+			i.UpdateSignaling(i.ListSignalers(), signals)
+			break
+		}
+	}
+}
+
+// Dedupe collapses duplicate edges (edges with the same source and
+// destination node ids) in all adjacency lists and returns the number of
+// duplicates removed. Signaling channels are keyed by node id, so
+// duplicate edges never have channels of their own to remove.
+func (g *Graph) Dedupe() int {
+	removed := 0
+
+	for n, l := range g.Top {
+		seen := make(map[int]bool)
+		deduped := make([]DGNode, 0, len(l))
+		for _, d := range l {
+			if seen[d.ID()] {
+				removed++
+				continue
+			}
+			seen[d.ID()] = true
+			deduped = append(deduped, d)
+		}
+		g.Top[n] = deduped
+	}
+
+	// the set of edges is unchanged, only the adjacency lists
+	if removed > 0 {
+		g.revision++
+	}
+
+	return removed
+}
+
 // CycleDetect will check whether a graph has cycles or not
 func (g *Graph) CycleDetect() bool {
 	var seen []DGNode
@@ -324,25 +634,27 @@ func (g *Graph) cycleDfs(start DGNode, seen, done []DGNode) (bool, []DGNode) {
 	return false, done
 }
 
-// GetAdjacents will return the list of nodes that a node is connected too
+// GetAdjacents will return the list of nodes that a node is connected too;
+// all of its dependents followed by all of its dependencies (each in sorted
+// order of node id)
 func (g *Graph) GetAdjacents(node DGNode) []DGNode {
 	var list []DGNode
 
-	for n, l := range g.Top {
-		if n.ID() == node.ID() {
-			// Add all dependents to list
-			for n2, l2 := range g.Top {
-				if contains(l2, n) {
-					list = append(list, n2)
-				}
-			}
-			// Add all dependencies to list
-			for _, np := range l {
-				list = append(list, np)
-			}
-		}
+	n, ok := g.GetNode(node.ID())
+	if !ok {
+		return list
 	}
 
+	// Add all dependents to list
+	dependents := g.Dependents(n)
+	sortNodes(dependents)
+	list = append(list, dependents...)
+
+	// Add all dependencies to list
+	dependencies := g.Dependencies(n)
+	sortNodes(dependencies)
+	list = append(list, dependencies...)
+
 	return list
 }
 
@@ -379,58 +691,15 @@ func (g *Graph) TotalityUnique() bool {
 	return true
 }
 
-// Covered returns true if all CDS nodes and edges are covered
-func (g *Graph) Covered() bool {
-	// grab all UI nodes
-	var uiSlice []UI
-	for v := range g.Top {
-		if v.GetType() == UINode {
-			uiSlice = append(uiSlice, v.(UI))
-		}
-	}
-
-	// grab all CDS nodes and edges
-	ds := g.DS
-	nodes := ds.ListNodes()
-	edges := ds.ListEdges()
-
-FIRST:
-	// for every node in the CDS
-	for _, v := range nodes {
-		// check that at least one UI contains it
-		for _, u := range uiSlice {
-			s := u.GetSection()
-			uiCDSNodesPointer := s.ListNodes()
-			uiCDSNodes := *uiCDSNodesPointer
-			// if UI contains node; check next CDS node
-			if ContainsNode(uiCDSNodes, v) {
-				continue FIRST
-			}
-		}
-
-		// if CDS node is checked in every UI and does not show up
-		return false
-	}
-
-SECOND:
-	// for every edge in the CDS
-	for _, v := range edges {
-		// check that at least one UI contains it
-		for _, u := range uiSlice {
-			s := u.GetSection()
-			uiCDSEdgesPointer := s.ListEdges()
-			uiCDSEdges := *uiCDSEdgesPointer
-			// if UI contains edge; check next CDS edge
-			if ContainsEdge(uiCDSEdges, v) {
-				continue SECOND
-			}
-		}
-
-		// if CDS edge is checked in every UI and does not show up
-		return false
+// Covered returns true if all CDS nodes and edges are covered; it will
+// return an error if the graph is not bound to a CDS
+func (g *Graph) Covered() (bool, error) {
+	if g.DS == nil {
+		return false, fmt.Errorf("No CDS bound to Dependency Graph")
 	}
 
-	return true
+	nodes, edges := g.CoverageGaps()
+	return len(nodes) == 0 && len(edges) == 0, nil
 }
 
 // AddVUI requires that the node return a true value for its IsVirtual method
@@ -438,44 +707,47 @@ func (g *Graph) AddVUI(node UI) (DGNode, error) {
 	var newNode DGNode
 
 	if !node.IsVirtual() {
-		return newNode, fmt.Errorf("Not a virtual node.")
+		return newNode, fmt.Errorf("Node %d: %w", node.ID(), ErrNotVirtual)
 	}
 
-	var nodeSlice []DGNode
-	for n := range g.Top {
-		nodeSlice = append(nodeSlice, n)
+	if _, ok := g.GetNode(node.ID()); ok {
+		return newNode, fmt.Errorf("Node %d: %w", node.ID(), ErrNodeExists)
 	}
 
-	if !contains(nodeSlice, node) {
-		g.Top[node.(DGNode)] = []DGNode{}
-	} else {
-		return newNode, fmt.Errorf("Node already exists in Dependency Graph")
+	if g.Strict {
+		if err := g.strictNode(node); err != nil {
+			return newNode, err
+		}
 	}
 
-	for n := range g.Top {
-		if n.ID() == node.ID() {
-			newNode = n
-		}
+	if err := g.insertNode(node); err != nil {
+		return newNode, err
 	}
+	g.recordNode(node.ID(), false)
 
-	return newNode, nil
+	return node, nil
 }
 
 // RemoveVUI ...
 func (g *Graph) RemoveVUI(n DGNode) error {
-	node, ok := n.(UI)
-	if !ok {
+	if _, ok := n.(UI); !ok {
 		return fmt.Errorf("Not a UI node")
 	}
 
-	if !node.IsVirtual() {
-		return fmt.Errorf("Not a virtual node")
+	if !isVirtualType(g.Type(n)) {
+		return fmt.Errorf("Node %d: %w", n.ID(), ErrNotVirtual)
+	}
+
+	if g.Strict {
+		if err := g.strictRemove(n); err != nil {
+			return err
+		}
 	}
 
 	for n1 := range g.Top {
 		if n1.ID() == n.ID() {
 			if len(g.Dependencies(n1)) != 0 {
-				return fmt.Errorf("VUI node still has dependencies")
+				return fmt.Errorf("VUI node %d: %w", n.ID(), ErrHasDependencies)
 			}
 		}
 	}
@@ -495,25 +767,244 @@ func (g *Graph) RemoveVUI(n DGNode) error {
 
 	// remove node from graph
 	delete(g.Top, n)
+	g.unindexNode(n.ID())
+	g.recordNode(n.ID(), true)
 
 	return nil
 }
 
+// RemoveUI removes a UI node (real or virtual) and all of its edges and
+// signaling channels from the graph, and then reports whether the CDS is
+// still covered along with the CDS nodes that became uncovered by the removal.
+func (g *Graph) RemoveUI(id int) (stillCovered bool, gaps NodeList, err error) {
+	gaps = make(NodeList, 0)
+
+	n, ok := g.GetNode(id)
+	if !ok {
+		return false, gaps, fmt.Errorf("Node %d: %w", id, ErrNodeNotFound)
+	}
+
+	if _, ok := n.(UI); !ok {
+		return false, gaps, fmt.Errorf("Not a UI node")
+	}
+
+	if g.DS == nil {
+		return false, gaps, fmt.Errorf("No CDS bound to Dependency Graph")
+	}
+
+	before, _ := g.CoverageGaps()
+
+	g.removeNode(n)
+
+	after, _ := g.CoverageGaps()
+	for _, v := range after {
+		if !ContainsNode(before, v) {
+			gaps = append(gaps, v)
+		}
+	}
+
+	covered, err := g.Covered()
+	return covered, gaps, err
+}
+
+// RemoveRealNode removes a node and all of its edges and signaling channels
+// from the graph. The node's channels to its dependents are closed, so that
+// dependents waiting for a signal from it unblock (see AwaitDependencies).
+// NOTE: the removed node must not signal its former dependents afterwards.
+func (g *Graph) RemoveRealNode(id int) error {
+	n, ok := g.GetNode(id)
+	if !ok {
+		return fmt.Errorf("Node %d: %w", id, ErrNodeNotFound)
+	}
+
+	g.removeNode(n)
+	return nil
+}
+
+// removeNode removes a node along with all of its edges (and their
+// signaling channels) to dependencies and dependents; channels to
+// dependents are closed as the final signal of the removed node
+func (g *Graph) removeNode(n DGNode) {
+	for _, d := range g.Dependents(n) {
+		c, ok := n.ListSignalers()[d.ID()]
+		g.RemoveRealEdge(d.ID(), n)
+		if ok {
+			delete(g.forwarded, c)
+			closeSignal(c)
+		}
+	}
+	for _, d := range g.Dependencies(n) {
+		g.RemoveRealEdge(n.ID(), d)
+	}
+
+	delete(g.Top, n)
+	g.unindexNode(n.ID())
+	delete(g.timeouts, n.ID())
+	delete(g.depModes, n.ID())
+	g.recordNode(n.ID(), true)
+}
+
+// PurgeVirtuals removes every virtual node (see IsVirtual) from the graph
+// along with its edges and signaling channels, as well as every VDG (and all
+// of its nodes), keeping the real graph, and returns the sorted ids of the
+// removed nodes. Virtual nodes that still have non-virtual dependents are not
+// removed; an error listing them is returned.
+func (g *Graph) PurgeVirtuals() (removed []int, err error) {
+	removed = make([]int, 0)
+
+	var virtuals []DGNode
+	for n := range g.Top {
+		if g.IsVirtual(n.ID()) {
+			virtuals = append(virtuals, n)
+		}
+	}
+	sortNodes(virtuals)
+
+	var refused []string
+	for _, n := range virtuals {
+		var real []int
+		for _, d := range g.Dependents(n) {
+			if !g.IsVirtual(d.ID()) {
+				real = append(real, d.ID())
+			}
+		}
+
+		if len(real) > 0 {
+			sort.Ints(real)
+			refused = append(refused, fmt.Sprintf("node %d has non-virtual dependents %v", n.ID(), real))
+			continue
+		}
+
+		g.removeNode(n)
+		removed = append(removed, n.ID())
+	}
+
+	// VDG nodes only have other VDG nodes as dependents, so VDGs are always
+	// removed whole
+	for _, vdg := range append([]*VDG(nil), g.VDG...) {
+		for v := range vdg.Top {
+			for _, d := range vdg.Dependencies(v) {
+				vdg.RemoveVirtualEdge(v.ID(), d)
+			}
+			v.UpdateSignaling(make(SignalingMap), make(SignalsMap))
+		}
+		for v := range vdg.Top {
+			vdg.RemoveVirtualNode(v)
+			removed = append(removed, v.ID())
+		}
+		g.RemoveVDG(vdg)
+	}
+	sort.Ints(removed)
+
+	if len(refused) > 0 {
+		err = fmt.Errorf("Could not remove virtual nodes: %s", strings.Join(refused, "; "))
+	}
+
+	return removed, err
+}
+
 // Dependents ...
 func (g *Graph) Dependents(n DGNode) []DGNode {
 	var list []DGNode
 
-	for i, v := range g.Top {
-		if i.ID() != n.ID() {
-			if contains(v, n) {
-				list = append(list, i)
+	for _, id := range g.dependentIDs(n.ID()) {
+		if id != n.ID() {
+			if d, ok := g.GetNode(id); ok {
+				list = append(list, d)
+			}
+		}
+	}
+
+	return list
+}
+
+// InDegree returns the number of dependents a node has
+func (g *Graph) InDegree(n DGNode) int {
+	return len(g.Dependents(n))
+}
+
+// Descendants returns all direct and transitive dependents of a node
+// (i.e. every node that directly or indirectly depends on it)
+func (g *Graph) Descendants(n DGNode) []DGNode {
+	var list []DGNode
+
+	visited := map[int]bool{n.ID(): true}
+	queue := []int{n.ID()}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range g.dependentIDs(id) {
+			if visited[dep] {
+				continue
+			}
+			visited[dep] = true
+			queue = append(queue, dep)
+			if d, ok := g.GetNode(dep); ok {
+				list = append(list, d)
 			}
 		}
 	}
 
+	sortNodes(list)
 	return list
 }
 
+// AbortImpact returns the nodes an abort starting at the given node would
+// reach if every dependent reacts with an "Abort Chain/Tree" (see Signal):
+// the node itself followed by all of its transitive dependents. Nothing is
+// signaled; it is a dry-run for previewing the impact of aborting a node.
+func (g *Graph) AbortImpact(startID int) []DGNode {
+	var list []DGNode
+
+	n, ok := g.GetNode(startID)
+	if !ok {
+		return list
+	}
+
+	return append(append(list, n), g.Descendants(n)...)
+}
+
+// PropagatePartial handles a PartialAbort signaled by the node with the
+// given id: every dependent that receives it is passed to decide, which
+// returns how the dependent reacts e.g. continuing with degraded input
+// (Started or Completed), retrying (AbortRetry), or aborting (Aborted or
+// PartialAbort). The dependents of nodes that abort are then passed to decide
+// as well, in breadth-first order and at most once each; nodes that continue
+// or retry stop the propagation. Every reaction is recorded with ReportSignal.
+func (g *Graph) PropagatePartial(startID int, decide func(DGNode) Signal) error {
+	start, ok := g.GetNode(startID)
+	if !ok {
+		return fmt.Errorf("Node %d: %w", startID, ErrNodeNotFound)
+	}
+
+	g.ReportSignal(startID, PartialAbort)
+
+	decided := map[int]bool{startID: true}
+	queue := []DGNode{start}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		deps := g.Dependents(n)
+		sortNodes(deps)
+		for _, d := range deps {
+			if decided[d.ID()] {
+				continue
+			}
+			decided[d.ID()] = true
+
+			s := decide(d)
+			g.ReportSignal(d.ID(), s)
+			if s == Aborted || s == PartialAbort {
+				queue = append(queue, d)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Dependencies ...
 func (g *Graph) Dependencies(n DGNode) []DGNode {
 	var list []DGNode
@@ -530,6 +1021,18 @@ func (g *Graph) Dependencies(n DGNode) []DGNode {
 	return list
 }
 
+// DependenciesE is the same as Dependencies but takes a node id and will
+// return an error if the node does not exist in the graph (as opposed to
+// a node that simply has no dependencies).
+func (g *Graph) DependenciesE(id int) ([]DGNode, error) {
+	n, ok := g.GetNode(id)
+	if !ok {
+		return []DGNode{}, fmt.Errorf("Node %d: %w", id, ErrNodeNotFound)
+	}
+
+	return g.Dependencies(n), nil
+}
+
 // Type will return the proper NodeType value for a given DGNode argument
 func (g *Graph) Type(n DGNode) NodeType {
 	if j, ok := n.(UI); ok {
@@ -550,3 +1053,31 @@ func (g *Graph) Type(n DGNode) NodeType {
 
 	return Unknown
 }
+
+// isVirtualType checks whether a NodeType is one of the virtual node types
+func isVirtualType(t NodeType) bool {
+	switch t {
+	case VUINode, VirtualTemporalNode, VDGNode:
+		return true
+	}
+
+	return false
+}
+
+// IsVirtual checks whether the node with the given id (in the graph or in
+// one of its VDGs) is a virtual node i.e. a VUI, virtual temporal or VDG node
+func (g *Graph) IsVirtual(id int) bool {
+	if n, ok := g.GetNode(id); ok {
+		return isVirtualType(g.Type(n))
+	}
+
+	for _, vdg := range g.VDG {
+		for v := range vdg.Top {
+			if v.ID() == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,161 @@
+package fabric
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// stateColors are the DOT fill colors used for nodes by signal state
+var stateColors = map[Signal]string{
+	Waiting:   "grey",
+	Started:   "yellow",
+	Completed: "green",
+	Aborted:   "red",
+}
+
+// WriteDOT writes the graph in Graphviz DOT format; every edge points from
+// a node to one of its dependencies.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	return g.WriteDOTWithState(w, nil)
+}
+
+// WriteDOTWithState is the same as WriteDOT but colors each node by the
+// signal state given for it (green=Completed, red=Aborted, yellow=Started,
+// grey=Waiting). The state is supplied by the caller (e.g. collected from
+// the nodes' signals); nodes without a state, or with any other signal
+// value, are left uncolored.
+func (g *Graph) WriteDOTWithState(w io.Writer, state map[int]Signal) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintln(b, "digraph fabric {")
+
+	var nodes []DGNode
+	for n := range g.Top {
+		nodes = append(nodes, n)
+	}
+	sortNodes(nodes)
+
+	for _, n := range nodes {
+		attrs := fmt.Sprintf("label=\"%d [%v]\"", n.ID(), n.GetType())
+		if s, ok := state[n.ID()]; ok {
+			if c, ok := stateColors[s]; ok {
+				attrs += fmt.Sprintf(", style=filled, fillcolor=%s", c)
+			}
+		}
+		fmt.Fprintf(b, "\t%d [%s];\n", n.ID(), attrs)
+	}
+
+	g.ForEachEdge(func(src, dst DGNode) bool {
+		fmt.Fprintf(b, "\t%d -> %d;\n", src.ID(), dst.ID())
+		return true
+	})
+
+	fmt.Fprintln(b, "}")
+
+	return b.Flush()
+}
+
+// WriteCDSDOT writes the nodes and edges of a CDS in Graphviz DOT format;
+// every edge points from its source node to its destination node.
+func WriteCDSDOT(c CDS, w io.Writer) error {
+	return writeCDSDOT(c, w, nil, nil)
+}
+
+// writeCDSDOT writes a CDS in DOT format with any extra attributes returned
+// for each node and edge (when the attribute functions are given)
+func writeCDSDOT(c CDS, w io.Writer, nodeAttrs func(Node) string, edgeAttrs func(Edge) string) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintln(b, "digraph cds {")
+
+	nodes := append(NodeList{}, c.ListNodes()...)
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID() < nodes[j].ID()
+	})
+
+	for _, n := range nodes {
+		attrs := fmt.Sprintf("label=\"%d\"", n.ID())
+		if nodeAttrs != nil {
+			if a := nodeAttrs(n); a != "" {
+				attrs += ", " + a
+			}
+		}
+		fmt.Fprintf(b, "\t%d [%s];\n", n.ID(), attrs)
+	}
+
+	for _, e := range c.ListEdges() {
+		attrs := fmt.Sprintf("label=\"%d\"", e.ID())
+		if edgeAttrs != nil {
+			if a := edgeAttrs(e); a != "" {
+				attrs += ", " + a
+			}
+		}
+		fmt.Fprintf(b, "\t%d -> %d [%s];\n", e.GetSource().ID(), e.GetDestination().ID(), attrs)
+	}
+
+	fmt.Fprintln(b, "}")
+
+	return b.Flush()
+}
+
+// coverageColors are the DOT colors used for the sections of UI nodes (in
+// order of UI node id, repeating if there are more UI nodes than colors)
+var coverageColors = []string{"lightblue", "palegreen", "gold", "plum", "orange", "cyan", "tan", "pink"}
+
+// WriteCoverageDOT writes the graph's CDS in Graphviz DOT format (see
+// WriteCDSDOT) with each node and edge colored by the UI section(s) covering
+// it and labeled with the ids of those UI nodes; elements covered by several
+// sections (see SharedSectionNodes) get several colors, and uncovered elements
+// (see CoverageGaps) are colored red. It will return an error if there is no
+// CDS bound to the graph.
+func (g *Graph) WriteCoverageDOT(w io.Writer) error {
+	ds, ok := g.CDS()
+	if !ok {
+		return fmt.Errorf("No CDS bound to Dependency Graph")
+	}
+
+	var uis []DGNode
+	for n := range g.Top {
+		if s, ok := n.(sectioned); ok && n.GetType() == UINode && s.GetSection() != nil {
+			uis = append(uis, n)
+		}
+	}
+	sortNodes(uis)
+
+	// covering returns the colors and ids of the UI nodes whose sections cover an element
+	covering := func(covers func(Section) bool) (string, string) {
+		var colors, ids []string
+		for i, u := range uis {
+			if covers(u.(sectioned).GetSection()) {
+				colors = append(colors, coverageColors[i%len(coverageColors)])
+				ids = append(ids, fmt.Sprint(u.ID()))
+			}
+		}
+		return strings.Join(colors, ":"), strings.Join(ids, ",")
+	}
+
+	nodeAttrs := func(n Node) string {
+		colors, ids := covering(func(s Section) bool { return ContainsNode(*s.ListNodes(), n) })
+		if colors == "" {
+			return "style=filled, fillcolor=red"
+		}
+		style := "filled"
+		if strings.Contains(colors, ":") {
+			style = "wedged"
+		}
+		return fmt.Sprintf("style=%s, fillcolor=\"%s\", xlabel=\"UI %s\"", style, colors, ids)
+	}
+
+	edgeAttrs := func(e Edge) string {
+		colors, ids := covering(func(s Section) bool { return ContainsEdge(*s.ListEdges(), e) })
+		if colors == "" {
+			return "color=red"
+		}
+		return fmt.Sprintf("color=\"%s\", xlabel=\"UI %s\"", colors, ids)
+	}
+
+	return writeCDSDOT(ds, w, nodeAttrs, edgeAttrs)
+}
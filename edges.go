@@ -0,0 +1,307 @@
+package fabric
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+)
+
+// edgeMeta is the metadata attached to a dependency edge
+type edgeMeta struct {
+	weight   float64
+	classes  map[string]bool // procedure classes whose signals flow across the edge
+	delivery DeliveryMode
+	dropped  int64 // signals dropped by Deliver or the Transport (accessed atomically)
+}
+
+// edgeKey returns the key of the edge from source to dest in the edge metadata map
+func edgeKey(source, dest int) [2]int {
+	return [2]int{source, dest}
+}
+
+// hasEdge checks whether the graph has an edge from source to dest
+func (g *Graph) hasEdge(source, dest int) bool {
+	n, ok := g.GetNode(source)
+	if !ok {
+		return false
+	}
+
+	for _, d := range g.Top[n] {
+		if d.ID() == dest {
+			return true
+		}
+	}
+	return false
+}
+
+// meta returns the metadata of an existing edge (creating it if needed)
+func (g *Graph) meta(source, dest int) (*edgeMeta, error) {
+	if !g.hasEdge(source, dest) {
+		return nil, fmt.Errorf("Edge from %d to %d does not exist in Dependency Graph", source, dest)
+	}
+
+	if g.edges == nil {
+		g.edges = make(map[[2]int]*edgeMeta)
+	}
+
+	m, ok := g.edges[edgeKey(source, dest)]
+	if !ok {
+		m = &edgeMeta{
+			weight: 1,
+		}
+		g.edges[edgeKey(source, dest)] = m
+	}
+	return m, nil
+}
+
+// SetEdgeWeight sets the (non-negative) weight of an existing edge, e.g. the
+// signaling latency between two nodes; edges have a weight of 1 by default
+func (g *Graph) SetEdgeWeight(source, dest int, weight float64) error {
+	if weight < 0 {
+		return fmt.Errorf("Edge weight must be non-negative")
+	}
+
+	m, err := g.meta(source, dest)
+	if err != nil {
+		return err
+	}
+
+	m.weight = weight
+	return nil
+}
+
+// EdgeWeight returns the weight of an edge (1 if it has not been set)
+func (g *Graph) EdgeWeight(source, dest int) float64 {
+	if m, ok := g.edges[edgeKey(source, dest)]; ok {
+		return m.weight
+	}
+	return 1
+}
+
+// SetEdgeClasses tags an existing edge with the procedure classes whose
+// signals flow across it (see SignalClass); this replaces any classes the
+// edge was previously tagged with.
+func (g *Graph) SetEdgeClasses(source, dest int, classes []string) error {
+	m, err := g.meta(source, dest)
+	if err != nil {
+		return err
+	}
+
+	m.classes = make(map[string]bool)
+	for _, c := range classes {
+		m.classes[c] = true
+	}
+	return nil
+}
+
+// EdgeClasses returns the sorted procedure classes an edge is tagged with
+func (g *Graph) EdgeClasses(source, dest int) []string {
+	classes := make([]string, 0)
+	if m, ok := g.edges[edgeKey(source, dest)]; ok {
+		for c := range m.classes {
+			classes = append(classes, c)
+		}
+	}
+
+	sort.Strings(classes)
+	return classes
+}
+
+// SignalClass sends a signal from a node only to the dependents whose edge
+// to the node is tagged with the given procedure class (see SetEdgeClasses),
+// and returns the number of dependents signaled. This enforces at the edge
+// level that only some procedures induce a dependent to respond, rather than
+// relying on dependents to filter the signals they receive.
+func (g *Graph) SignalClass(nodeID int, class string, s NodeSignal) (int, error) {
+	n, ok := g.GetNode(nodeID)
+	if !ok {
+		return 0, fmt.Errorf("Node %d: %w", nodeID, ErrNodeNotFound)
+	}
+
+	sent := 0
+	signalers := n.ListSignalers()
+	for _, d := range g.DependentsForClass(nodeID, class) {
+		c, ok := signalers[d.ID()]
+		if !ok {
+			continue
+		}
+		c <- s
+		sent++
+	}
+
+	return sent, nil
+}
+
+// DependentsForClass returns the dependents of a node that react to signals
+// of a procedure class (see SetEdgeClasses) i.e. the nodes SignalClass
+// would signal.
+func (g *Graph) DependentsForClass(id int, class string) []DGNode {
+	dependents := make([]DGNode, 0)
+
+	n, ok := g.GetNode(id)
+	if !ok {
+		return dependents
+	}
+
+	for _, d := range g.Dependents(n) {
+		if m, ok := g.edges[edgeKey(d.ID(), id)]; ok && m.classes[class] {
+			dependents = append(dependents, d)
+		}
+	}
+
+	return dependents
+}
+
+// ShortestWeightedPath uses Dijkstra's algorithm to find the path from one
+// node to another (following edges from dependent to dependency) with the
+// lowest total edge weight, and returns the path and its total weight.
+// NOTE: signals travel in the opposite direction of edges; the path a
+// signal from node A takes to reach node B is ShortestWeightedPath(B, A) reversed.
+func (g *Graph) ShortestWeightedPath(from, to int) ([]DGNode, float64, error) {
+	var path []DGNode
+
+	start, ok := g.GetNode(from)
+	if !ok {
+		return path, 0, fmt.Errorf("Node %d: %w", from, ErrNodeNotFound)
+	}
+	if _, ok := g.GetNode(to); !ok {
+		return path, 0, fmt.Errorf("Node %d: %w", to, ErrNodeNotFound)
+	}
+
+	dist := map[int]float64{from: 0}
+	prev := make(map[int]int)
+	done := make(map[int]bool)
+
+	pq := &distQueue{{node: start, dist: 0}}
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(distItem)
+		id := item.node.ID()
+		if done[id] {
+			continue
+		}
+		done[id] = true
+
+		if id == to {
+			break
+		}
+
+		for _, d := range g.Top[item.node] {
+			k, ok := g.GetNode(d.ID())
+			if !ok || done[k.ID()] {
+				continue
+			}
+			nd := item.dist + g.EdgeWeight(id, k.ID())
+			if cur, ok := dist[k.ID()]; !ok || nd < cur {
+				dist[k.ID()] = nd
+				prev[k.ID()] = id
+				heap.Push(pq, distItem{node: k, dist: nd})
+			}
+		}
+	}
+
+	if !done[to] {
+		return path, 0, fmt.Errorf("Node %d is not reachable from node %d", to, from)
+	}
+
+	for id := to; ; id = prev[id] {
+		n, _ := g.GetNode(id)
+		path = append([]DGNode{n}, path...)
+		if id == from {
+			break
+		}
+	}
+
+	return path, dist[to], nil
+}
+
+// distItem is a node and its tentative distance in Dijkstra's algorithm
+type distItem struct {
+	node DGNode
+	dist float64
+}
+
+// distQueue is a min-heap of distItems (satisfies heap.Interface)
+type distQueue []distItem
+
+func (q distQueue) Len() int { return len(q) }
+
+func (q distQueue) Less(i, j int) bool {
+	if q[i].dist == q[j].dist {
+		return q[i].node.ID() < q[j].node.ID()
+	}
+	return q[i].dist < q[j].dist
+}
+
+func (q distQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *distQueue) Push(x interface{}) { *q = append(*q, x.(distItem)) }
+
+func (q *distQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// DGEdge is a dependency graph edge from a (dependent) source node to a
+// (dependency) destination node along with its metadata. It is named DGEdge
+// to distinguish it from CDS edges (see Edge).
+type DGEdge struct {
+	Source  int
+	Dest    int
+	Weight  float64
+	Classes []string
+}
+
+// edge returns the DGEdge from source to dest
+func (g *Graph) edge(source, dest int) DGEdge {
+	return DGEdge{
+		Source:  source,
+		Dest:    dest,
+		Weight:  g.EdgeWeight(source, dest),
+		Classes: g.EdgeClasses(source, dest),
+	}
+}
+
+// IncomingEdges returns the edges pointing into a node (i.e. from its
+// dependents) in order of source id
+func (g *Graph) IncomingEdges(id int) []DGEdge {
+	edges := make([]DGEdge, 0)
+
+	if _, ok := g.GetNode(id); !ok {
+		return edges
+	}
+
+	sources := make([]int, len(g.dependentIDs(id)))
+	copy(sources, g.dependentIDs(id))
+	sort.Ints(sources)
+	for _, src := range sources {
+		if _, ok := g.GetNode(src); ok {
+			edges = append(edges, g.edge(src, id))
+		}
+	}
+
+	return edges
+}
+
+// OutgoingEdges returns the (distinct) edges from a node to its dependencies
+// in order of destination id
+func (g *Graph) OutgoingEdges(id int) []DGEdge {
+	edges := make([]DGEdge, 0)
+
+	n, ok := g.GetNode(id)
+	if !ok {
+		return edges
+	}
+
+	deps := g.Dependencies(n)
+	sortNodes(deps)
+	for i, d := range deps {
+		if i > 0 && deps[i-1].ID() == d.ID() {
+			continue
+		}
+		edges = append(edges, g.edge(id, d.ID()))
+	}
+
+	return edges
+}
@@ -0,0 +1,14 @@
+package fabric
+
+import "errors"
+
+// Errors returned (wrapped with additional context) by graph operations;
+// use errors.Is to check for them.
+var (
+	ErrNodeExists         = errors.New("Node already exists in Dependency Graph")
+	ErrNodeNotFound       = errors.New("Node does not exist in Dependency Graph")
+	ErrNotVirtual         = errors.New("Not a virtual node")
+	ErrHasDependencies    = errors.New("Node still has dependencies")
+	ErrCycle              = errors.New("Graph contains a cycle")
+	ErrDependenciesFailed = errors.New("All dependencies failed")
+)
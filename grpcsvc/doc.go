@@ -0,0 +1,10 @@
+// Package grpcsvc provides a gRPC server exposing read-only queries over a
+// fabric dependency graph, so other services can inspect the live
+// dependency structure without linking the fabric package.
+//
+// The service is defined in graph.proto; the Go stubs in the pb package are
+// generated with protoc (protoc-gen-go and protoc-gen-go-grpc must be
+// installed) by running `go generate` in this directory.
+package grpcsvc
+
+//go:generate protoc --go_out=. --go_opt=module=github.com/JKhawaja/fabric/grpcsvc --go-grpc_out=. --go-grpc_opt=module=github.com/JKhawaja/fabric/grpcsvc graph.proto
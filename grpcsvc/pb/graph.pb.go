@@ -0,0 +1,466 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: graph.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_graph_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_graph_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_graph_proto_rawDescGZIP(), []int{0}
+}
+
+type NodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeRequest) Reset() {
+	*x = NodeRequest{}
+	mi := &file_graph_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeRequest) ProtoMessage() {}
+
+func (x *NodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_graph_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeRequest.ProtoReflect.Descriptor instead.
+func (*NodeRequest) Descriptor() ([]byte, []int) {
+	return file_graph_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NodeRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type Node struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Priority      int64                  `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	Virtual       bool                   `protobuf:"varint,4,opt,name=virtual,proto3" json:"virtual,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_graph_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_graph_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_graph_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Node) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Node) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Node) GetPriority() int64 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Node) GetVirtual() bool {
+	if x != nil {
+		return x.Virtual
+	}
+	return false
+}
+
+type NodeList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*Node                `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeList) Reset() {
+	*x = NodeList{}
+	mi := &file_graph_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeList) ProtoMessage() {}
+
+func (x *NodeList) ProtoReflect() protoreflect.Message {
+	mi := &file_graph_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeList.ProtoReflect.Descriptor instead.
+func (*NodeList) Descriptor() ([]byte, []int) {
+	return file_graph_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *NodeList) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+type GraphStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         int64                  `protobuf:"varint,1,opt,name=nodes,proto3" json:"nodes,omitempty"`
+	Edges         int64                  `protobuf:"varint,2,opt,name=edges,proto3" json:"edges,omitempty"`
+	Roots         int64                  `protobuf:"varint,3,opt,name=roots,proto3" json:"roots,omitempty"`
+	Leaves        int64                  `protobuf:"varint,4,opt,name=leaves,proto3" json:"leaves,omitempty"`
+	Types         map[string]int64       `protobuf:"bytes,5,rep,name=types,proto3" json:"types,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Vdgs          int64                  `protobuf:"varint,6,opt,name=vdgs,proto3" json:"vdgs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GraphStats) Reset() {
+	*x = GraphStats{}
+	mi := &file_graph_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GraphStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GraphStats) ProtoMessage() {}
+
+func (x *GraphStats) ProtoReflect() protoreflect.Message {
+	mi := &file_graph_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GraphStats.ProtoReflect.Descriptor instead.
+func (*GraphStats) Descriptor() ([]byte, []int) {
+	return file_graph_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GraphStats) GetNodes() int64 {
+	if x != nil {
+		return x.Nodes
+	}
+	return 0
+}
+
+func (x *GraphStats) GetEdges() int64 {
+	if x != nil {
+		return x.Edges
+	}
+	return 0
+}
+
+func (x *GraphStats) GetRoots() int64 {
+	if x != nil {
+		return x.Roots
+	}
+	return 0
+}
+
+func (x *GraphStats) GetLeaves() int64 {
+	if x != nil {
+		return x.Leaves
+	}
+	return 0
+}
+
+func (x *GraphStats) GetTypes() map[string]int64 {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *GraphStats) GetVdgs() int64 {
+	if x != nil {
+		return x.Vdgs
+	}
+	return 0
+}
+
+type Coverage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Covered        bool                   `protobuf:"varint,1,opt,name=covered,proto3" json:"covered,omitempty"`
+	UncoveredNodes []int64                `protobuf:"varint,2,rep,packed,name=uncovered_nodes,json=uncoveredNodes,proto3" json:"uncovered_nodes,omitempty"`
+	UncoveredEdges []int64                `protobuf:"varint,3,rep,packed,name=uncovered_edges,json=uncoveredEdges,proto3" json:"uncovered_edges,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Coverage) Reset() {
+	*x = Coverage{}
+	mi := &file_graph_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Coverage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Coverage) ProtoMessage() {}
+
+func (x *Coverage) ProtoReflect() protoreflect.Message {
+	mi := &file_graph_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Coverage.ProtoReflect.Descriptor instead.
+func (*Coverage) Descriptor() ([]byte, []int) {
+	return file_graph_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Coverage) GetCovered() bool {
+	if x != nil {
+		return x.Covered
+	}
+	return false
+}
+
+func (x *Coverage) GetUncoveredNodes() []int64 {
+	if x != nil {
+		return x.UncoveredNodes
+	}
+	return nil
+}
+
+func (x *Coverage) GetUncoveredEdges() []int64 {
+	if x != nil {
+		return x.UncoveredEdges
+	}
+	return nil
+}
+
+var File_graph_proto protoreflect.FileDescriptor
+
+const file_graph_proto_rawDesc = "" +
+	"\n" +
+	"\vgraph.proto\x12\x0efabric.grpcsvc\"\a\n" +
+	"\x05Empty\"\x1d\n" +
+	"\vNodeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"`\n" +
+	"\x04Node\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1a\n" +
+	"\bpriority\x18\x03 \x01(\x03R\bpriority\x12\x18\n" +
+	"\avirtual\x18\x04 \x01(\bR\avirtual\"6\n" +
+	"\bNodeList\x12*\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x14.fabric.grpcsvc.NodeR\x05nodes\"\xf1\x01\n" +
+	"\n" +
+	"GraphStats\x12\x14\n" +
+	"\x05nodes\x18\x01 \x01(\x03R\x05nodes\x12\x14\n" +
+	"\x05edges\x18\x02 \x01(\x03R\x05edges\x12\x14\n" +
+	"\x05roots\x18\x03 \x01(\x03R\x05roots\x12\x16\n" +
+	"\x06leaves\x18\x04 \x01(\x03R\x06leaves\x12;\n" +
+	"\x05types\x18\x05 \x03(\v2%.fabric.grpcsvc.GraphStats.TypesEntryR\x05types\x12\x12\n" +
+	"\x04vdgs\x18\x06 \x01(\x03R\x04vdgs\x1a8\n" +
+	"\n" +
+	"TypesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"v\n" +
+	"\bCoverage\x12\x18\n" +
+	"\acovered\x18\x01 \x01(\bR\acovered\x12'\n" +
+	"\x0funcovered_nodes\x18\x02 \x03(\x03R\x0euncoveredNodes\x12'\n" +
+	"\x0funcovered_edges\x18\x03 \x03(\x03R\x0euncoveredEdges2\x94\x03\n" +
+	"\fGraphService\x12<\n" +
+	"\aGetNode\x12\x1b.fabric.grpcsvc.NodeRequest\x1a\x14.fabric.grpcsvc.Node\x12C\n" +
+	"\n" +
+	"Dependents\x12\x1b.fabric.grpcsvc.NodeRequest\x1a\x18.fabric.grpcsvc.NodeList\x12E\n" +
+	"\fDependencies\x12\x1b.fabric.grpcsvc.NodeRequest\x1a\x18.fabric.grpcsvc.NodeList\x12;\n" +
+	"\bTopoSort\x12\x15.fabric.grpcsvc.Empty\x1a\x18.fabric.grpcsvc.NodeList\x12:\n" +
+	"\x05Stats\x12\x15.fabric.grpcsvc.Empty\x1a\x1a.fabric.grpcsvc.GraphStats\x12A\n" +
+	"\x0eCoverageReport\x12\x15.fabric.grpcsvc.Empty\x1a\x18.fabric.grpcsvc.CoverageB'Z%github.com/JKhawaja/fabric/grpcsvc/pbb\x06proto3"
+
+var (
+	file_graph_proto_rawDescOnce sync.Once
+	file_graph_proto_rawDescData []byte
+)
+
+func file_graph_proto_rawDescGZIP() []byte {
+	file_graph_proto_rawDescOnce.Do(func() {
+		file_graph_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_graph_proto_rawDesc), len(file_graph_proto_rawDesc)))
+	})
+	return file_graph_proto_rawDescData
+}
+
+var file_graph_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_graph_proto_goTypes = []any{
+	(*Empty)(nil),       // 0: fabric.grpcsvc.Empty
+	(*NodeRequest)(nil), // 1: fabric.grpcsvc.NodeRequest
+	(*Node)(nil),        // 2: fabric.grpcsvc.Node
+	(*NodeList)(nil),    // 3: fabric.grpcsvc.NodeList
+	(*GraphStats)(nil),  // 4: fabric.grpcsvc.GraphStats
+	(*Coverage)(nil),    // 5: fabric.grpcsvc.Coverage
+	nil,                 // 6: fabric.grpcsvc.GraphStats.TypesEntry
+}
+var file_graph_proto_depIdxs = []int32{
+	2, // 0: fabric.grpcsvc.NodeList.nodes:type_name -> fabric.grpcsvc.Node
+	6, // 1: fabric.grpcsvc.GraphStats.types:type_name -> fabric.grpcsvc.GraphStats.TypesEntry
+	1, // 2: fabric.grpcsvc.GraphService.GetNode:input_type -> fabric.grpcsvc.NodeRequest
+	1, // 3: fabric.grpcsvc.GraphService.Dependents:input_type -> fabric.grpcsvc.NodeRequest
+	1, // 4: fabric.grpcsvc.GraphService.Dependencies:input_type -> fabric.grpcsvc.NodeRequest
+	0, // 5: fabric.grpcsvc.GraphService.TopoSort:input_type -> fabric.grpcsvc.Empty
+	0, // 6: fabric.grpcsvc.GraphService.Stats:input_type -> fabric.grpcsvc.Empty
+	0, // 7: fabric.grpcsvc.GraphService.CoverageReport:input_type -> fabric.grpcsvc.Empty
+	2, // 8: fabric.grpcsvc.GraphService.GetNode:output_type -> fabric.grpcsvc.Node
+	3, // 9: fabric.grpcsvc.GraphService.Dependents:output_type -> fabric.grpcsvc.NodeList
+	3, // 10: fabric.grpcsvc.GraphService.Dependencies:output_type -> fabric.grpcsvc.NodeList
+	3, // 11: fabric.grpcsvc.GraphService.TopoSort:output_type -> fabric.grpcsvc.NodeList
+	4, // 12: fabric.grpcsvc.GraphService.Stats:output_type -> fabric.grpcsvc.GraphStats
+	5, // 13: fabric.grpcsvc.GraphService.CoverageReport:output_type -> fabric.grpcsvc.Coverage
+	8, // [8:14] is the sub-list for method output_type
+	2, // [2:8] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_graph_proto_init() }
+func file_graph_proto_init() {
+	if File_graph_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_graph_proto_rawDesc), len(file_graph_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_graph_proto_goTypes,
+		DependencyIndexes: file_graph_proto_depIdxs,
+		MessageInfos:      file_graph_proto_msgTypes,
+	}.Build()
+	File_graph_proto = out.File
+	file_graph_proto_goTypes = nil
+	file_graph_proto_depIdxs = nil
+}
@@ -0,0 +1,311 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: graph.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GraphService_GetNode_FullMethodName        = "/fabric.grpcsvc.GraphService/GetNode"
+	GraphService_Dependents_FullMethodName     = "/fabric.grpcsvc.GraphService/Dependents"
+	GraphService_Dependencies_FullMethodName   = "/fabric.grpcsvc.GraphService/Dependencies"
+	GraphService_TopoSort_FullMethodName       = "/fabric.grpcsvc.GraphService/TopoSort"
+	GraphService_Stats_FullMethodName          = "/fabric.grpcsvc.GraphService/Stats"
+	GraphService_CoverageReport_FullMethodName = "/fabric.grpcsvc.GraphService/CoverageReport"
+)
+
+// GraphServiceClient is the client API for GraphService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GraphServiceClient interface {
+	GetNode(ctx context.Context, in *NodeRequest, opts ...grpc.CallOption) (*Node, error)
+	Dependents(ctx context.Context, in *NodeRequest, opts ...grpc.CallOption) (*NodeList, error)
+	Dependencies(ctx context.Context, in *NodeRequest, opts ...grpc.CallOption) (*NodeList, error)
+	TopoSort(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*NodeList, error)
+	Stats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GraphStats, error)
+	CoverageReport(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Coverage, error)
+}
+
+type graphServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGraphServiceClient(cc grpc.ClientConnInterface) GraphServiceClient {
+	return &graphServiceClient{cc}
+}
+
+func (c *graphServiceClient) GetNode(ctx context.Context, in *NodeRequest, opts ...grpc.CallOption) (*Node, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Node)
+	err := c.cc.Invoke(ctx, GraphService_GetNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *graphServiceClient) Dependents(ctx context.Context, in *NodeRequest, opts ...grpc.CallOption) (*NodeList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeList)
+	err := c.cc.Invoke(ctx, GraphService_Dependents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *graphServiceClient) Dependencies(ctx context.Context, in *NodeRequest, opts ...grpc.CallOption) (*NodeList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeList)
+	err := c.cc.Invoke(ctx, GraphService_Dependencies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *graphServiceClient) TopoSort(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*NodeList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NodeList)
+	err := c.cc.Invoke(ctx, GraphService_TopoSort_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *graphServiceClient) Stats(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GraphStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GraphStats)
+	err := c.cc.Invoke(ctx, GraphService_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *graphServiceClient) CoverageReport(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Coverage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Coverage)
+	err := c.cc.Invoke(ctx, GraphService_CoverageReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GraphServiceServer is the server API for GraphService service.
+// All implementations must embed UnimplementedGraphServiceServer
+// for forward compatibility.
+type GraphServiceServer interface {
+	GetNode(context.Context, *NodeRequest) (*Node, error)
+	Dependents(context.Context, *NodeRequest) (*NodeList, error)
+	Dependencies(context.Context, *NodeRequest) (*NodeList, error)
+	TopoSort(context.Context, *Empty) (*NodeList, error)
+	Stats(context.Context, *Empty) (*GraphStats, error)
+	CoverageReport(context.Context, *Empty) (*Coverage, error)
+	mustEmbedUnimplementedGraphServiceServer()
+}
+
+// UnimplementedGraphServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGraphServiceServer struct{}
+
+func (UnimplementedGraphServiceServer) GetNode(context.Context, *NodeRequest) (*Node, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetNode not implemented")
+}
+func (UnimplementedGraphServiceServer) Dependents(context.Context, *NodeRequest) (*NodeList, error) {
+	return nil, status.Error(codes.Unimplemented, "method Dependents not implemented")
+}
+func (UnimplementedGraphServiceServer) Dependencies(context.Context, *NodeRequest) (*NodeList, error) {
+	return nil, status.Error(codes.Unimplemented, "method Dependencies not implemented")
+}
+func (UnimplementedGraphServiceServer) TopoSort(context.Context, *Empty) (*NodeList, error) {
+	return nil, status.Error(codes.Unimplemented, "method TopoSort not implemented")
+}
+func (UnimplementedGraphServiceServer) Stats(context.Context, *Empty) (*GraphStats, error) {
+	return nil, status.Error(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedGraphServiceServer) CoverageReport(context.Context, *Empty) (*Coverage, error) {
+	return nil, status.Error(codes.Unimplemented, "method CoverageReport not implemented")
+}
+func (UnimplementedGraphServiceServer) mustEmbedUnimplementedGraphServiceServer() {}
+func (UnimplementedGraphServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeGraphServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GraphServiceServer will
+// result in compilation errors.
+type UnsafeGraphServiceServer interface {
+	mustEmbedUnimplementedGraphServiceServer()
+}
+
+func RegisterGraphServiceServer(s grpc.ServiceRegistrar, srv GraphServiceServer) {
+	// If the following call panics, it indicates UnimplementedGraphServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GraphService_ServiceDesc, srv)
+}
+
+func _GraphService_GetNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GraphServiceServer).GetNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GraphService_GetNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GraphServiceServer).GetNode(ctx, req.(*NodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GraphService_Dependents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GraphServiceServer).Dependents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GraphService_Dependents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GraphServiceServer).Dependents(ctx, req.(*NodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GraphService_Dependencies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GraphServiceServer).Dependencies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GraphService_Dependencies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GraphServiceServer).Dependencies(ctx, req.(*NodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GraphService_TopoSort_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GraphServiceServer).TopoSort(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GraphService_TopoSort_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GraphServiceServer).TopoSort(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GraphService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GraphServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GraphService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GraphServiceServer).Stats(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GraphService_CoverageReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GraphServiceServer).CoverageReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GraphService_CoverageReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GraphServiceServer).CoverageReport(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GraphService_ServiceDesc is the grpc.ServiceDesc for GraphService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GraphService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fabric.grpcsvc.GraphService",
+	HandlerType: (*GraphServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNode",
+			Handler:    _GraphService_GetNode_Handler,
+		},
+		{
+			MethodName: "Dependents",
+			Handler:    _GraphService_Dependents_Handler,
+		},
+		{
+			MethodName: "Dependencies",
+			Handler:    _GraphService_Dependencies_Handler,
+		},
+		{
+			MethodName: "TopoSort",
+			Handler:    _GraphService_TopoSort_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _GraphService_Stats_Handler,
+		},
+		{
+			MethodName: "CoverageReport",
+			Handler:    _GraphService_CoverageReport_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "graph.proto",
+}
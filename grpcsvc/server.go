@@ -0,0 +1,152 @@
+package grpcsvc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/JKhawaja/fabric"
+	"github.com/JKhawaja/fabric/grpcsvc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the GraphService by wrapping a graph.
+// NOTE: the graph is not safe for concurrent use (even queries may rebuild
+// its lazy indexes, see Graph.Reindex), so every request holds Lock; any
+// code mutating the graph while the server is running should hold it too.
+type Server struct {
+	pb.UnimplementedGraphServiceServer
+	Graph *fabric.Graph
+	Lock  *sync.Mutex
+}
+
+// NewServer ...
+func NewServer(g *fabric.Graph) *Server {
+	return &Server{
+		Graph: g,
+		Lock:  &sync.Mutex{},
+	}
+}
+
+// Register creates a Server for the graph and registers it with a gRPC server
+func Register(gs *grpc.Server, g *fabric.Graph) *Server {
+	s := NewServer(g)
+	pb.RegisterGraphServiceServer(gs, s)
+	return s
+}
+
+func (s *Server) node(id int64) (fabric.DGNode, error) {
+	n, ok := s.Graph.GetNode(int(id))
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "node %d does not exist in dependency graph", id)
+	}
+	return n, nil
+}
+
+func (s *Server) toNode(n fabric.DGNode) *pb.Node {
+	return &pb.Node{
+		Id:       int64(n.ID()),
+		Type:     n.GetType().String(),
+		Priority: int64(n.GetPriority()),
+		Virtual:  s.Graph.IsVirtual(n.ID()),
+	}
+}
+
+func (s *Server) toNodeList(l []fabric.DGNode) *pb.NodeList {
+	list := &pb.NodeList{}
+	for _, n := range l {
+		list.Nodes = append(list.Nodes, s.toNode(n))
+	}
+	return list
+}
+
+// GetNode ...
+func (s *Server) GetNode(ctx context.Context, req *pb.NodeRequest) (*pb.Node, error) {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	n, err := s.node(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toNode(n), nil
+}
+
+// Dependents ...
+func (s *Server) Dependents(ctx context.Context, req *pb.NodeRequest) (*pb.NodeList, error) {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	n, err := s.node(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toNodeList(s.Graph.Dependents(n)), nil
+}
+
+// Dependencies ...
+func (s *Server) Dependencies(ctx context.Context, req *pb.NodeRequest) (*pb.NodeList, error) {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	n, err := s.node(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toNodeList(s.Graph.Dependencies(n)), nil
+}
+
+// TopoSort ...
+func (s *Server) TopoSort(ctx context.Context, req *pb.Empty) (*pb.NodeList, error) {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	order, err := s.Graph.TopoSort()
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return s.toNodeList(order), nil
+}
+
+// Stats ...
+func (s *Server) Stats(ctx context.Context, req *pb.Empty) (*pb.GraphStats, error) {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	stats := s.Graph.Stats()
+	res := &pb.GraphStats{
+		Nodes:  int64(stats.Nodes),
+		Edges:  int64(stats.Edges),
+		Roots:  int64(stats.Roots),
+		Leaves: int64(stats.Leaves),
+		Types:  make(map[string]int64),
+		Vdgs:   int64(stats.VDGs),
+	}
+	for t, c := range stats.Types {
+		res.Types[t.String()] = int64(c)
+	}
+	return res, nil
+}
+
+// CoverageReport ...
+func (s *Server) CoverageReport(ctx context.Context, req *pb.Empty) (*pb.Coverage, error) {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	if _, ok := s.Graph.CDS(); !ok {
+		return nil, status.Error(codes.FailedPrecondition, "no CDS bound to dependency graph")
+	}
+
+	report := s.Graph.CoverageReport()
+	res := &pb.Coverage{
+		Covered: report.Covered,
+	}
+	for _, n := range report.UncoveredNodes {
+		res.UncoveredNodes = append(res.UncoveredNodes, int64(n.ID()))
+	}
+	for _, e := range report.UncoveredEdges {
+		res.UncoveredEdges = append(res.UncoveredEdges, int64(e.ID()))
+	}
+	return res, nil
+}
@@ -0,0 +1,66 @@
+// +build test
+
+package grpcsvc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/JKhawaja/fabric"
+	"github.com/JKhawaja/fabric/grpcsvc"
+	"github.com/JKhawaja/fabric/grpcsvc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServer(t *testing.T) {
+	// 2 depends on 1
+	graph := fabric.NewGraph()
+	n1, _ := graph.AddRealNode(fabric.NewAggregatorNode(1, fabric.UINode))
+	if _, err := graph.AddRealNode(fabric.NewAggregatorNode(2, fabric.UINode)); err != nil {
+		t.Fatalf("Could not add node to graph: %v", err)
+	}
+	graph.AddRealEdge(2, n1)
+
+	lis := bufconn.Listen(1024 * 1024)
+	gs := grpc.NewServer()
+	grpcsvc.Register(gs, graph)
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Could not connect to server: %v", err)
+	}
+	defer conn.Close()
+	client := pb.NewGraphServiceClient(conn)
+	ctx := context.Background()
+
+	n, err := client.GetNode(ctx, &pb.NodeRequest{Id: 1})
+	if err != nil || n.Id != 1 || n.Type != fabric.UINode.String() || n.Virtual {
+		t.Fatalf("Incorrect node: %v, %v", n, err)
+	}
+
+	deps, err := client.Dependents(ctx, &pb.NodeRequest{Id: 1})
+	if err != nil || len(deps.Nodes) != 1 || deps.Nodes[0].Id != 2 {
+		t.Fatalf("Incorrect dependents: %v, %v", deps, err)
+	}
+
+	stats, err := client.Stats(ctx, &pb.Empty{})
+	if err != nil || stats.Nodes != 2 || stats.Edges != 1 {
+		t.Fatalf("Incorrect stats: %v, %v", stats, err)
+	}
+
+	if _, err := client.GetNode(ctx, &pb.NodeRequest{Id: 3}); status.Code(err) != codes.NotFound {
+		t.Fatalf("Incorrect error for missing node: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package fabric
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// signalLog records the latest signal state of each node in a graph
+type signalLog struct {
+	mu     sync.Mutex
+	states map[int]Signal
+	since  map[int]time.Time // time each node entered its current state
+}
+
+// logInit guards the lazy creation of a graph's signal log
+var logInit sync.Mutex
+
+// signalLog returns the graph's signal log, creating it if necessary
+func (g *Graph) signalLog() *signalLog {
+	logInit.Lock()
+	defer logInit.Unlock()
+
+	if g.log == nil {
+		g.log = &signalLog{
+			states: make(map[int]Signal),
+			since:  make(map[int]time.Time),
+		}
+	}
+
+	return g.log
+}
+
+// ReportSignal records the signal a node has most recently sent to its
+// dependents; nodes should call it (from their own threads) alongside Signal()
+// so that Health() can report on the state of a running graph.
+func (g *Graph) ReportSignal(id int, s Signal) {
+	l := g.signalLog()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if cur, ok := l.states[id]; ok && cur == s {
+		return
+	}
+	l.states[id] = s
+	l.since[id] = time.Now()
+}
+
+// HealthStatus is a snapshot of the execution state of a running graph
+type HealthStatus struct {
+	States   map[Signal]int `json:"states"`   // number of nodes in each signal state
+	Waiting  []int          `json:"waiting"`  // ids of the Waiting nodes, longest waiting first
+	Deadlock bool           `json:"deadlock"` // whether the graph can no longer make progress
+}
+
+// Health reports how many nodes are in each signal state, which nodes have
+// been Waiting the longest, and whether a deadlock was detected. Nodes that
+// have not reported a signal (see ReportSignal) are considered Waiting since
+// the start of execution. A deadlock is detected if the graph contains a
+// dependency cycle.
+func (g *Graph) Health() HealthStatus {
+	l := g.signalLog()
+	l.mu.Lock()
+	states := make(map[int]Signal, len(l.states))
+	since := make(map[int]time.Time, len(l.since))
+	for id, s := range l.states {
+		states[id] = s
+		since[id] = l.since[id]
+	}
+	l.mu.Unlock()
+
+	h := HealthStatus{
+		States:  make(map[Signal]int),
+		Waiting: make([]int, 0),
+	}
+
+	for id := range g.nodesByID() {
+		s := states[id]
+		h.States[s]++
+
+		if s == Waiting {
+			h.Waiting = append(h.Waiting, id)
+		}
+	}
+
+	// unreported nodes have a zero time and therefore sort first
+	sort.Slice(h.Waiting, func(i, j int) bool {
+		a, b := since[h.Waiting[i]], since[h.Waiting[j]]
+		if a.Equal(b) {
+			return h.Waiting[i] < h.Waiting[j]
+		}
+		return a.Before(b)
+	})
+
+	// nodes on a dependency cycle wait on each other forever
+	if err := g.topoWalk(func(DGNode) bool { return true }); err != nil {
+		h.Deadlock = true
+	}
+
+	return h
+}
+
+// InFlightCount returns the number of graph nodes whose last signal in a
+// state map (e.g. collected from the nodes' signals) is Started or
+// AbortRetry i.e. nodes that have started but not reached a terminal state
+func (g *Graph) InFlightCount(state map[int]Signal) int {
+	count := 0
+	for id, s := range state {
+		if _, ok := g.GetNode(id); !ok {
+			continue
+		}
+		if s == Started || s == AbortRetry {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,139 @@
+package fabric
+
+import "fmt"
+
+// GetNode returns the graph node (i.e. the key in Top) with the given id
+func (g *Graph) GetNode(id int) (DGNode, bool) {
+	if n, ok := g.ids[id]; ok && len(g.ids) == len(g.Top) {
+		if _, ok := g.Top[n]; ok {
+			return n, true
+		}
+	}
+
+	// index is stale (Top has been modified directly); rebuild it
+	g.reindex()
+	n, ok := g.ids[id]
+	return n, ok
+}
+
+// Reindex rebuilds the graph's id and dependents indexes; it only
+// needs to be called after Top has been modified directly (all Graph
+// methods keep the indexes up to date).
+func (g *Graph) Reindex() {
+	g.reindex()
+	g.reindexDependents()
+}
+
+// reindex rebuilds the id index from the keys of Top
+func (g *Graph) reindex() {
+	g.ids = make(map[int]DGNode, len(g.Top))
+	for n := range g.Top {
+		g.ids[n.ID()] = n
+	}
+
+	// the dependents index is stale as well
+	g.dependents = nil
+}
+
+// reindexDependents rebuilds the reverse adjacency index (node id to the ids
+// of its dependents) from the adjacency lists in Top; dangling edges (to
+// nodes no longer in Top) are not indexed
+func (g *Graph) reindexDependents() {
+	g.dependents = make(map[int][]int)
+	for n, l := range g.Top {
+		seen := make(map[int]bool)
+		for _, d := range l {
+			if _, ok := g.ids[d.ID()]; ok && !seen[d.ID()] {
+				seen[d.ID()] = true
+				g.dependents[d.ID()] = append(g.dependents[d.ID()], n.ID())
+			}
+		}
+	}
+}
+
+// dependentIDs returns the ids of all dependents of a node from the
+// reverse adjacency index
+func (g *Graph) dependentIDs(id int) []int {
+	if g.dependents == nil || len(g.ids) != len(g.Top) {
+		g.Reindex()
+	}
+	return g.dependents[id]
+}
+
+// indexEdge adds an edge to the dependents index
+func (g *Graph) indexEdge(source, dest int) {
+	if g.dependents == nil {
+		return
+	}
+	for _, id := range g.dependents[dest] {
+		if id == source {
+			return
+		}
+	}
+	g.dependents[dest] = append(g.dependents[dest], source)
+}
+
+// unindexEdge removes an edge from the dependents index
+func (g *Graph) unindexEdge(source, dest int) {
+	if g.dependents == nil {
+		return
+	}
+	l := g.dependents[dest]
+	for i, id := range l {
+		if id == source {
+			g.dependents[dest] = append(l[:i], l[i+1:]...)
+			break
+		}
+	}
+	if len(g.dependents[dest]) == 0 {
+		delete(g.dependents, dest)
+	}
+}
+
+// indexNode adds a node to the id index
+func (g *Graph) indexNode(n DGNode) {
+	if g.ids == nil {
+		g.reindex()
+	}
+	g.ids[n.ID()] = n
+}
+
+// unindexNode removes a node id from the id index and the dependents index
+// (both as a dependency and as a dependent)
+func (g *Graph) unindexNode(id int) {
+	delete(g.ids, id)
+
+	if g.dependents == nil {
+		return
+	}
+	delete(g.dependents, id)
+	for dest := range g.dependents {
+		g.unindexEdge(id, dest)
+	}
+}
+
+// checkHashable will return an error (instead of panicking) if a node's
+// concrete type cannot be used as a map key (e.g. a struct value containing a map)
+func checkHashable(node DGNode) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Node type is not comparable and cannot be used in the graph topology: %v", r)
+		}
+	}()
+
+	_ = map[DGNode]bool{node: true}
+
+	return nil
+}
+
+// insertNode adds a node with no dependencies to Top and the id index
+func (g *Graph) insertNode(node DGNode) error {
+	if err := checkHashable(node); err != nil {
+		return err
+	}
+
+	g.Top[node] = []DGNode{}
+	g.indexNode(node)
+
+	return nil
+}
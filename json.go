@@ -0,0 +1,144 @@
+package fabric
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// jsonNode is the JSON representation of a graph node
+type jsonNode struct {
+	ID       int     `json:"id"`
+	Type     string  `json:"type"`
+	Priority int     `json:"priority"`
+	Signal   *Signal `json:"signal,omitempty"` // latest reported signal (see ReportSignal)
+}
+
+// jsonVirtual is the JSON representation of the lifecycle state of a virtual node in a VDG
+type jsonVirtual struct {
+	ID   int  `json:"id"`
+	Life Life `json:"life"`
+}
+
+// jsonGraph is the JSON representation of a graph
+type jsonGraph struct {
+	Nodes    []jsonNode    `json:"nodes"`
+	Edges    [][2]int      `json:"edges"`              // (source id, destination id) pairs
+	Virtuals []jsonVirtual `json:"virtuals,omitempty"` // only included by MarshalStateful
+}
+
+// toJSON returns the JSON representation of the graph's topology, and of its
+// runtime state if stateful is true
+func (g *Graph) toJSON(stateful bool) jsonGraph {
+	j := jsonGraph{
+		Nodes: make([]jsonNode, 0),
+		Edges: make([][2]int, 0),
+	}
+
+	var states map[int]Signal
+	if stateful {
+		l := g.signalLog()
+		l.mu.Lock()
+		states = make(map[int]Signal, len(l.states))
+		for id, s := range l.states {
+			states[id] = s
+		}
+		l.mu.Unlock()
+	}
+
+	var nodes []DGNode
+	for n := range g.Top {
+		nodes = append(nodes, n)
+	}
+	sortNodes(nodes)
+	for _, n := range nodes {
+		jn := jsonNode{
+			ID:       n.ID(),
+			Type:     g.Type(n).String(),
+			Priority: n.GetPriority(),
+		}
+		if s, ok := states[n.ID()]; ok {
+			jn.Signal = &s
+		}
+		j.Nodes = append(j.Nodes, jn)
+	}
+
+	seen := make(map[[2]int]bool)
+	g.ForEachEdge(func(src, dst DGNode) bool {
+		e := [2]int{src.ID(), dst.ID()}
+		if !seen[e] {
+			seen[e] = true
+			j.Edges = append(j.Edges, e)
+		}
+		return true
+	})
+
+	if stateful {
+		snapshot := g.LifecycleSnapshot()
+		var ids []int
+		for id := range snapshot {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			j.Virtuals = append(j.Virtuals, jsonVirtual{ID: id, Life: snapshot[id]})
+		}
+	}
+
+	return j
+}
+
+// MarshalJSON encodes the graph's topology (its nodes and edges) as JSON
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.toJSON(false))
+}
+
+// MarshalStateful is the same as MarshalJSON but also includes the latest
+// reported signal state of each node (see ReportSignal) and the lifecycle
+// state of each virtual node in the graph's VDGs, so that a partially
+// executed graph can be checkpointed and resumed (see RestoreStateful).
+func (g *Graph) MarshalStateful() ([]byte, error) {
+	return json.Marshal(g.toJSON(true))
+}
+
+// RestoreStateful restores the signal and lifecycle states from a checkpoint
+// created with MarshalStateful onto a graph with the same topology (e.g. one
+// rebuilt after a restart); it will return an error, without restoring
+// anything, if the checkpoint does not match the graph's topology.
+func (g *Graph) RestoreStateful(data []byte) error {
+	var j jsonGraph
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	current := g.toJSON(false)
+	if len(current.Nodes) != len(j.Nodes) || len(current.Edges) != len(j.Edges) {
+		return fmt.Errorf("Checkpoint topology does not match Dependency Graph")
+	}
+	for i, n := range j.Nodes {
+		if current.Nodes[i].ID != n.ID {
+			return fmt.Errorf("Checkpoint node %d: %w", n.ID, ErrNodeNotFound)
+		}
+	}
+	for i, e := range j.Edges {
+		if current.Edges[i] != e {
+			return fmt.Errorf("Checkpoint edge from %d to %d does not exist in Dependency Graph", e[0], e[1])
+		}
+	}
+
+	lives := make(map[int]Life)
+	for _, v := range j.Virtuals {
+		lives[v.ID] = v.Life
+	}
+	if err := g.RestoreLifecycles(lives); err != nil {
+		return err
+	}
+
+	for _, n := range j.Nodes {
+		if n.Signal != nil {
+			g.ReportSignal(n.ID, *n.Signal)
+		}
+	}
+
+	return nil
+}
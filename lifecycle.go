@@ -0,0 +1,225 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Life defines the possible lifecycle states of a virtual node
+type Life int
+
+const (
+	// Idle is a virtual node that has been created but has not begun execution
+	Idle Life = iota
+	// Running is a virtual node that is executing
+	Running
+	// Complete is a virtual node that has finished execution (and can be reaped)
+	Complete
+)
+
+// LifecycleNode can be satisfied by Virtual nodes in order to expose their
+// lifecycle state; signaling to and from virtual nodes is only allowed while
+// they are Running. Virtual nodes that do not satisfy this interface are
+// always considered Running.
+type LifecycleNode interface {
+	Lifecycle() Life
+}
+
+// lifeOf returns the lifecycle state of a virtual node
+func lifeOf(v Virtual) Life {
+	if l, ok := v.(LifecycleNode); ok {
+		return l.Lifecycle()
+	}
+	return Running
+}
+
+// LifecycleSetter can be satisfied by Virtual nodes whose lifecycle state
+// can be restored (see RestoreLifecycles)
+type LifecycleSetter interface {
+	SetLifecycle(Life)
+}
+
+// virtuals returns every virtual node in all VDGs of the graph by id
+func (g *Graph) virtuals() map[int]Virtual {
+	nodes := make(map[int]Virtual)
+	for _, vdg := range g.VDG {
+		for v := range vdg.Top {
+			nodes[v.ID()] = v
+		}
+	}
+	return nodes
+}
+
+// LifecycleSnapshot returns the lifecycle state of every virtual node in all
+// VDGs of the graph, e.g. to checkpoint a running system
+func (g *Graph) LifecycleSnapshot() map[int]Life {
+	snapshot := make(map[int]Life)
+	for id, v := range g.virtuals() {
+		snapshot[id] = lifeOf(v)
+	}
+	return snapshot
+}
+
+// RestoreLifecycles sets the lifecycle state of virtual nodes from a snapshot
+// (see LifecycleSnapshot). It will return an error, without restoring any
+// state, if an id is not a virtual node in the graph or if a node's state
+// differs from the snapshot and the node does not satisfy LifecycleSetter.
+func (g *Graph) RestoreLifecycles(snapshot map[int]Life) error {
+	nodes := g.virtuals()
+
+	ids := make([]int, 0, len(snapshot))
+	for id := range snapshot {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		v, ok := nodes[id]
+		if !ok {
+			return fmt.Errorf("Virtual node %d: %w", id, ErrNodeNotFound)
+		}
+		if _, ok := v.(LifecycleSetter); !ok && lifeOf(v) != snapshot[id] {
+			return fmt.Errorf("Virtual node %d does not support restoring its lifecycle", id)
+		}
+	}
+
+	for _, id := range ids {
+		if s, ok := nodes[id].(LifecycleSetter); ok {
+			s.SetLifecycle(snapshot[id])
+		}
+	}
+
+	return nil
+}
+
+// LifecycleManager tracks the lifecycle state of a virtual node and enforces
+// its state machine (Idle -> Running -> Complete, or Running -> Idle when
+// execution is cancelled or fails); virtual nodes can embed a
+// *LifecycleManager to satisfy LifecycleNode and LifecycleSetter.
+type LifecycleManager struct {
+	mu   sync.Mutex
+	life Life
+}
+
+// NewLifecycleManager creates a LifecycleManager in the Idle state
+func NewLifecycleManager() *LifecycleManager {
+	return &LifecycleManager{
+		life: Idle,
+	}
+}
+
+// Lifecycle ...
+func (v *LifecycleManager) Lifecycle() Life {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.life
+}
+
+// SetLifecycle sets the lifecycle state without enforcing the state machine
+// (e.g. when restoring a checkpoint)
+func (v *LifecycleManager) SetLifecycle(l Life) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.life = l
+}
+
+// Transition moves to a new lifecycle state; it will return an error if the
+// transition is not allowed by the state machine
+func (v *LifecycleManager) Transition(to Life) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	allowed := false
+	switch v.life {
+	case Idle:
+		allowed = to == Running
+	case Running:
+		allowed = to == Complete || to == Idle
+	}
+
+	if !allowed {
+		return fmt.Errorf("Lifecycle transition from %d to %d is not allowed", v.life, to)
+	}
+
+	v.life = to
+	return nil
+}
+
+// RunContext transitions from Idle to Running, runs work, and then
+// transitions to Complete if work succeeds; if the context is cancelled or
+// work fails the state goes back to Idle (so that it can be run again) and
+// the error is returned.
+func (v *LifecycleManager) RunContext(ctx context.Context, work func(context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := v.Transition(Running); err != nil {
+		return err
+	}
+
+	err := work(ctx)
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		v.Transition(Idle)
+		return err
+	}
+
+	return v.Transition(Complete)
+}
+
+// ActiveVirtuals returns all Running virtual nodes in all VDGs of the graph
+func (g *Graph) ActiveVirtuals() []Virtual {
+	var list []Virtual
+
+	for _, vdg := range g.VDG {
+		for v := range vdg.Top {
+			if lifeOf(v) == Running {
+				list = append(list, v)
+			}
+		}
+	}
+
+	return list
+}
+
+// SignalVirtual sends a signal from a virtual node to all of its Running
+// dependents; dependents that are Idle or Complete are skipped. It will return
+// an error if the sending node is not Running, and otherwise returns the number
+// of dependents signaled.
+func (g *VDG) SignalVirtual(srcID int, s NodeSignal) (int, error) {
+	var src Virtual
+	for n := range g.Top {
+		if n.ID() == srcID {
+			src = n
+		}
+	}
+
+	if src == nil {
+		return 0, fmt.Errorf("Virtual node %d: %w", srcID, ErrNodeNotFound)
+	}
+
+	if lifeOf(src) != Running {
+		return 0, fmt.Errorf("Virtual node %d is not running and cannot signal", srcID)
+	}
+
+	sent := 0
+	signalers := src.ListSignalers()
+	for _, d := range g.Dependents(src) {
+		c, ok := signalers[d.ID()]
+		if !ok || lifeOf(d) != Running {
+			continue
+		}
+		c <- s
+		sent++
+	}
+
+	return sent, nil
+}
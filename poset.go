@@ -1,5 +1,7 @@
 package fabric
 
+import "fmt"
+
 // Poset is an object that wraps a dependency graph
 type Poset interface {
 	// Graph should return a pointer to the graph that our POSET object is "wrapping"
@@ -13,6 +15,24 @@ type Poset interface {
 	Order(DGNode) error
 }
 
+// BuildFromPoset creates a graph from a list of nodes using a Poset's
+// InitGraph method, verifies that the graph is acyclic and wires all of
+// its signaling channels; the returned graph is ready to be executed.
+func BuildFromPoset(p Poset, nodes []DGNode) (*Graph, error) {
+	g := p.InitGraph(nodes)
+	if g == nil {
+		return nil, fmt.Errorf("Poset did not create a graph")
+	}
+
+	if err := g.topoWalk(func(DGNode) bool { return true }); err != nil {
+		return nil, err
+	}
+
+	g.SignalsAndSignalers()
+
+	return g, nil
+}
+
 // VPoset is an object that wraps a virtual dependency graph
 type VPoset interface {
 	// VDG should return a pointer to the VDG that our VPOSET object is "wrapping"
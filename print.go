@@ -0,0 +1,68 @@
+package fabric
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// String returns the name of a NodeType
+func (t NodeType) String() string {
+	switch t {
+	case UINode:
+		return "UI"
+	case TemporalNode:
+		return "Temporal"
+	case VirtualTemporalNode:
+		return "VirtualTemporal"
+	case VUINode:
+		return "VUI"
+	case VDGNode:
+		return "VDG"
+	}
+
+	return "Unknown"
+}
+
+// String prints every root boundary node of the graph with its dependencies
+// as an indented tree; edges back to a node already on the current path are
+// printed as a cycle marker e.g. `(cycle→ID)`. Nodes in cycles that can not
+// be reached from any root boundary are printed as trees of their own.
+func (g *Graph) String() string {
+	var buf bytes.Buffer
+
+	tops := append(g.Roots(), g.UnreachableFromRoots()...)
+	printed := make(map[int]bool)
+	for _, n := range tops {
+		if !printed[n.ID()] {
+			g.printNode(&buf, n, 0, make(map[int]bool), printed)
+		}
+	}
+
+	return buf.String()
+}
+
+// Recursive Depth-First-Search; used for printing the graph
+func (g *Graph) printNode(buf *bytes.Buffer, n DGNode, depth int, path, printed map[int]bool) {
+	indent := strings.Repeat("  ", depth)
+	if path[n.ID()] {
+		fmt.Fprintf(buf, "%s(cycle→%d)\n", indent, n.ID())
+		return
+	}
+
+	fmt.Fprintf(buf, "%s%d [%v]\n", indent, n.ID(), n.GetType())
+	printed[n.ID()] = true
+
+	k, ok := g.GetNode(n.ID())
+	if !ok {
+		return
+	}
+
+	path[n.ID()] = true
+	deps := g.Dependencies(k)
+	sortNodes(deps)
+	for _, d := range deps {
+		g.printNode(buf, d, depth+1, path, printed)
+	}
+	delete(path, n.ID())
+}
@@ -0,0 +1,88 @@
+package fabric
+
+// ReadOnlyGraph exposes only the query methods of a Graph (see Graph.ReadOnly)
+type ReadOnlyGraph interface {
+	GetNode(id int) (DGNode, bool)
+	Dependents(n DGNode) []DGNode
+	Dependencies(n DGNode) []DGNode
+	Descendants(n DGNode) []DGNode
+	Roots() []DGNode
+	ForEachEdge(fn func(src, dst DGNode) bool)
+	TopoSort() ([]DGNode, error)
+	Stats() GraphStats
+	Type(n DGNode) NodeType
+	IsVirtual(id int) bool
+	EdgeWeight(source, dest int) float64
+	EdgeClasses(source, dest int) []string
+	Revision() uint64
+	String() string
+}
+
+// readOnly wraps a graph so that only its query methods can be reached
+type readOnly struct {
+	g *Graph
+}
+
+// ReadOnly returns a view of the graph that can be handed to code that must
+// not modify its topology (e.g. plugins); the view can not be converted back
+// into a *Graph. NOTE: the nodes returned by the view are the graph's nodes
+// themselves, so their own methods (e.g. UpdateSignaling) are still reachable.
+func (g *Graph) ReadOnly() ReadOnlyGraph {
+	return readOnly{g: g}
+}
+
+func (r readOnly) GetNode(id int) (DGNode, bool) {
+	return r.g.GetNode(id)
+}
+
+func (r readOnly) Dependents(n DGNode) []DGNode {
+	return r.g.Dependents(n)
+}
+
+func (r readOnly) Dependencies(n DGNode) []DGNode {
+	return r.g.Dependencies(n)
+}
+
+func (r readOnly) Descendants(n DGNode) []DGNode {
+	return r.g.Descendants(n)
+}
+
+func (r readOnly) Roots() []DGNode {
+	return r.g.Roots()
+}
+
+func (r readOnly) ForEachEdge(fn func(src, dst DGNode) bool) {
+	r.g.ForEachEdge(fn)
+}
+
+func (r readOnly) TopoSort() ([]DGNode, error) {
+	return r.g.TopoSort()
+}
+
+func (r readOnly) Stats() GraphStats {
+	return r.g.Stats()
+}
+
+func (r readOnly) Type(n DGNode) NodeType {
+	return r.g.Type(n)
+}
+
+func (r readOnly) IsVirtual(id int) bool {
+	return r.g.IsVirtual(id)
+}
+
+func (r readOnly) EdgeWeight(source, dest int) float64 {
+	return r.g.EdgeWeight(source, dest)
+}
+
+func (r readOnly) EdgeClasses(source, dest int) []string {
+	return r.g.EdgeClasses(source, dest)
+}
+
+func (r readOnly) Revision() uint64 {
+	return r.g.Revision()
+}
+
+func (r readOnly) String() string {
+	return r.g.String()
+}
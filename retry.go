@@ -0,0 +1,57 @@
+package fabric
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryPolicy computes the delays between retries of an access procedure
+// after an AbortRetry signal from one of its dependencies
+type RetryPolicy struct {
+	Base       time.Duration // delay before the first retry
+	Max        time.Duration // maximum delay (no maximum if <= 0)
+	Multiplier float64       // growth of the delay per attempt (2 if <= 0)
+	Jitter     float64       // in [0,1]: delays are randomized within ±Jitter of the computed delay
+	Seed       int64         // seed of the jitter random number generator
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// Backoff returns the delay before a retry attempt (starting at attempt 0).
+// With Jitter, nodes that aborted at the same time do not retry in lock-step;
+// policies with the same Seed produce the same sequence of delays.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	m := p.Multiplier
+	if m <= 0 {
+		m = 2
+	}
+
+	delay := float64(p.Base) * math.Pow(m, float64(attempt))
+	if p.Max > 0 && delay > float64(p.Max) {
+		delay = float64(p.Max)
+	}
+
+	jitter := math.Min(math.Max(p.Jitter, 0), 1)
+	if jitter > 0 {
+		p.mu.Lock()
+		if p.rng == nil {
+			p.rng = rand.New(rand.NewSource(p.Seed))
+		}
+		r := p.rng.Float64()
+		p.mu.Unlock()
+
+		delay *= 1 + jitter*(2*r-1)
+	}
+
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,117 @@
+package fabric
+
+import "sort"
+
+// change is a single topology mutation recorded in a graph's change log
+type change struct {
+	rev     uint64
+	node    int    // id of the added or removed node (if isEdge is false)
+	edge    [2]int // source and destination ids of the added or removed edge
+	isEdge  bool
+	removed bool
+}
+
+// GraphDiff describes the net topology changes made to a graph between two revisions
+type GraphDiff struct {
+	AddedNodes   []int
+	RemovedNodes []int
+	AddedEdges   [][2]int // (source id, destination id) pairs
+	RemovedEdges [][2]int
+}
+
+// Empty returns true if the diff contains no changes
+func (d GraphDiff) Empty() bool {
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 &&
+		len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// Revision returns the graph's revision counter, which increments on every
+// topology mutation made through Graph methods (direct modifications of Top
+// are not tracked).
+func (g *Graph) Revision() uint64 {
+	return g.revision
+}
+
+// recordNode logs the addition or removal of a node as a new revision
+func (g *Graph) recordNode(id int, removed bool) {
+	g.revision++
+	g.changes = append(g.changes, change{
+		rev:     g.revision,
+		node:    id,
+		removed: removed,
+	})
+}
+
+// recordEdge logs the addition or removal of an edge as a new revision
+func (g *Graph) recordEdge(source, dest int, removed bool) {
+	g.revision++
+	g.changes = append(g.changes, change{
+		rev:     g.revision,
+		edge:    [2]int{source, dest},
+		isEdge:  true,
+		removed: removed,
+	})
+}
+
+// ChangedSince returns the net topology changes made after the given
+// revision, along with the current revision (to be used for the next call).
+// A node or edge that was added and removed again (or vice versa) since
+// the given revision is not part of the diff.
+func (g *Graph) ChangedSince(rev uint64) (GraphDiff, uint64) {
+	var diff GraphDiff
+
+	// existed before rev (opposite of the first change) vs. exists now (last change)
+	type state struct{ before, after bool }
+	nodes := make(map[int]*state)
+	edges := make(map[[2]int]*state)
+
+	for _, c := range g.changes {
+		if c.rev <= rev {
+			continue
+		}
+
+		if c.isEdge {
+			if s, ok := edges[c.edge]; ok {
+				s.after = !c.removed
+			} else {
+				edges[c.edge] = &state{before: c.removed, after: !c.removed}
+			}
+			continue
+		}
+
+		if s, ok := nodes[c.node]; ok {
+			s.after = !c.removed
+		} else {
+			nodes[c.node] = &state{before: c.removed, after: !c.removed}
+		}
+	}
+
+	for id, s := range nodes {
+		if s.before == s.after {
+			continue
+		}
+		if s.after {
+			diff.AddedNodes = append(diff.AddedNodes, id)
+		} else {
+			diff.RemovedNodes = append(diff.RemovedNodes, id)
+		}
+	}
+
+	for e, s := range edges {
+		if s.before == s.after {
+			continue
+		}
+		if s.after {
+			diff.AddedEdges = append(diff.AddedEdges, e)
+		} else {
+			diff.RemovedEdges = append(diff.RemovedEdges, e)
+		}
+	}
+
+	sort.Ints(diff.AddedNodes)
+	sort.Ints(diff.RemovedNodes)
+	sortEdges(diff.AddedEdges)
+	sortEdges(diff.RemovedEdges)
+
+	return diff, g.revision
+}
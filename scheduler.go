@@ -0,0 +1,445 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// TieBreak is the policy a Scheduler uses to choose between ready nodes
+// that share the same priority
+type TieBreak int
+
+const (
+	// FIFO chooses the node that became ready first
+	FIFO TieBreak = iota
+	// WeightedRoundRobin shares turns between the nodes in proportion to their
+	// weights (see Scheduler.SetWeight) using smooth weighted round-robin
+	WeightedRoundRobin
+)
+
+// readyNode is a node in a Scheduler's ready queue
+type readyNode struct {
+	node DGNode
+	seq  uint64 // enqueue order
+}
+
+// Scheduler hands out the nodes of a graph in an order that respects
+// their dependencies: a node becomes ready once all of its dependencies
+// (or any one of them, see SetDependencyMode) have been marked complete, and Next returns the ready node with the
+// highest priority (see GetPriority), breaking ties by the configured
+// TieBreak policy.
+// NOTE: the graph must not be modified while it is being scheduled.
+type Scheduler struct {
+	g        *Graph
+	tieBreak TieBreak
+
+	ready     []readyNode
+	seq       uint64
+	remaining map[int]int // number of incomplete dependencies of each node
+	complete  map[int]bool
+	deps      map[int]int // number of dependencies of each node
+	failed    map[int]int // number of aborted dependencies of each node
+	aborted   map[int]bool
+
+	weights map[int]int // weighted round-robin weights (default 1)
+	current map[int]int // weighted round-robin current weights
+}
+
+// NewScheduler creates a Scheduler for a graph with all leaf boundary
+// nodes ready (enqueued in order of node id)
+func NewScheduler(g *Graph) *Scheduler {
+	s := &Scheduler{
+		g:         g,
+		remaining: make(map[int]int),
+		complete:  make(map[int]bool),
+		deps:      make(map[int]int),
+		failed:    make(map[int]int),
+		aborted:   make(map[int]bool),
+		weights:   make(map[int]int),
+		current:   make(map[int]int),
+	}
+
+	keys := g.nodesByID()
+	var leaves []DGNode
+	for id, n := range keys {
+		seen := make(map[int]bool)
+		for _, d := range g.Top[n] {
+			if _, ok := keys[d.ID()]; ok && !seen[d.ID()] {
+				seen[d.ID()] = true
+				s.remaining[id]++
+			}
+		}
+
+		s.deps[id] = s.remaining[id]
+		if s.remaining[id] == 0 {
+			leaves = append(leaves, n)
+		} else if g.DependencyMode(id) == Any {
+			s.remaining[id] = 1
+		}
+	}
+
+	sortNodes(leaves)
+	for _, n := range leaves {
+		s.enqueue(n)
+	}
+
+	return s
+}
+
+// SetTieBreak sets the policy used to choose between ready nodes with the same priority
+func (s *Scheduler) SetTieBreak(policy TieBreak) {
+	s.tieBreak = policy
+}
+
+// SetWeight sets the weighted round-robin weight of a node (weights < 1 are treated as 1)
+func (s *Scheduler) SetWeight(id int, weight int) {
+	s.weights[id] = weight
+}
+
+// weight returns the weighted round-robin weight of a node
+func (s *Scheduler) weight(id int) int {
+	if w, ok := s.weights[id]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+// enqueue adds a node to the ready queue
+func (s *Scheduler) enqueue(n DGNode) {
+	s.seq++
+	s.ready = append(s.ready, readyNode{
+		node: n,
+		seq:  s.seq,
+	})
+}
+
+// Ready returns the number of nodes that are ready to be scheduled
+func (s *Scheduler) Ready() int {
+	return len(s.ready)
+}
+
+// Next removes and returns the next ready node; false is returned if no
+// node is ready
+func (s *Scheduler) Next() (DGNode, bool) {
+	if len(s.ready) == 0 {
+		return nil, false
+	}
+
+	// candidates are the ready nodes with the highest priority (the ready
+	// queue is kept in enqueue order)
+	top := s.ready[0].node.GetPriority()
+	for _, r := range s.ready {
+		if p := r.node.GetPriority(); p > top {
+			top = p
+		}
+	}
+
+	var candidates []int // indexes into the ready queue
+	for i, r := range s.ready {
+		if r.node.GetPriority() == top {
+			candidates = append(candidates, i)
+		}
+	}
+
+	chosen := candidates[0]
+	if s.tieBreak == WeightedRoundRobin && len(candidates) > 1 {
+		total := 0
+		for _, i := range candidates {
+			id := s.ready[i].node.ID()
+			s.current[id] += s.weight(id)
+			total += s.weight(id)
+			if s.current[id] > s.current[s.ready[chosen].node.ID()] {
+				chosen = i
+			}
+		}
+		s.current[s.ready[chosen].node.ID()] -= total
+	}
+
+	n := s.ready[chosen].node
+	s.ready = append(s.ready[:chosen], s.ready[chosen+1:]...)
+
+	return n, true
+}
+
+// Requeue puts a node that was returned by Next back into the ready
+// queue (e.g. so that it can run again); weighted round-robin shares turns
+// between requeued nodes in proportion to their weights.
+func (s *Scheduler) Requeue(n DGNode) {
+	s.enqueue(n)
+}
+
+// Complete marks a node as complete; any of its dependents whose
+// dependencies are now all complete become ready (in order of node id)
+func (s *Scheduler) Complete(id int) {
+	if s.complete[id] {
+		return
+	}
+	s.complete[id] = true
+
+	deps := make([]int, len(s.g.dependentIDs(id)))
+	copy(deps, s.g.dependentIDs(id))
+	sort.Ints(deps)
+
+	for _, dep := range deps {
+		s.remaining[dep]--
+		if s.remaining[dep] == 0 {
+			if n, ok := s.g.GetNode(dep); ok {
+				s.enqueue(n)
+			}
+		}
+	}
+}
+
+// Abort marks a node as aborted; any of its dependents with the Any
+// dependency mode whose dependencies have now all aborted can no longer
+// become ready, so they are marked as aborted as well. The ids of the
+// dependents aborted this way (transitively) are returned in order.
+func (s *Scheduler) Abort(id int) []int {
+	if s.aborted[id] || s.complete[id] {
+		return nil
+	}
+	s.aborted[id] = true
+
+	deps := make([]int, len(s.g.dependentIDs(id)))
+	copy(deps, s.g.dependentIDs(id))
+	sort.Ints(deps)
+
+	var aborted []int
+	for _, dep := range deps {
+		s.failed[dep]++
+		if s.g.DependencyMode(dep) != Any || s.remaining[dep] == 0 || s.failed[dep] < s.deps[dep] {
+			continue
+		}
+		aborted = append(aborted, dep)
+		aborted = append(aborted, s.Abort(dep)...)
+	}
+
+	return aborted
+}
+
+// ReadyNodes emits each graph node exactly once, as soon as all of its
+// dependencies (or any one of them, see SetDependencyMode) have signaled Completed on its incoming signaling channels
+// (leaf boundary nodes are emitted immediately, in order of node id); it is
+// the event-driven counterpart of Scheduler.Next for worker pools that range
+// over the returned channel. The channel is closed once every node has been
+// emitted (or can no longer become ready) or the context is cancelled. A
+// node can no longer become ready once a dependency sends another terminal
+// signal (with Any, once all of them have), and it is then reported as
+// Aborted (see ReportSignal).
+// NOTE: ReadyNodes consumes the signals on the incoming channels, so the
+// nodes themselves must not read from them, and the graph must not be
+// modified while the channel is being consumed.
+func (g *Graph) ReadyNodes(ctx context.Context) <-chan DGNode {
+	ready := make(chan DGNode)
+
+	var wg sync.WaitGroup
+	emit := func(n DGNode) {
+		select {
+		case ready <- n:
+		case <-ctx.Done():
+		}
+	}
+
+	keys := g.nodesByID()
+	var leaves []DGNode
+	for _, n := range keys {
+		seen := make(map[int]bool)
+		var deps []int
+		for _, d := range g.Top[n] {
+			if _, ok := keys[d.ID()]; ok && !seen[d.ID()] {
+				seen[d.ID()] = true
+				deps = append(deps, d.ID())
+			}
+		}
+
+		if len(deps) == 0 {
+			leaves = append(leaves, n)
+			continue
+		}
+
+		// count down the dependencies that have completed, and count those that
+		// ended otherwise
+		var mu sync.Mutex
+		remaining, failed, limit := len(deps), 0, 1
+		if g.DependencyMode(n.ID()) == Any {
+			remaining, limit = 1, len(deps)
+		}
+		signals := n.ListSignals()
+		for _, id := range deps {
+			c, ok := signals[id]
+			if !ok {
+				continue
+			}
+
+			wg.Add(1)
+			go func(n DGNode, c <-chan NodeSignal) {
+				defer wg.Done()
+				for {
+					select {
+					case s, ok := <-c:
+						if !ok {
+							return
+						}
+						if s.Effective() != Completed {
+							if !s.IsTerminal() {
+								continue
+							}
+
+							mu.Lock()
+							failed++
+							abort := failed == limit && remaining > 0
+							mu.Unlock()
+
+							if abort {
+								g.ReportSignal(n.ID(), Aborted)
+							}
+							return
+						}
+
+						mu.Lock()
+						remaining--
+						done := remaining == 0 && (limit > 1 || failed == 0)
+						mu.Unlock()
+
+						if done {
+							emit(n)
+						}
+						return
+					case <-ctx.Done():
+						return
+					}
+				}
+			}(n, c)
+		}
+	}
+
+	sortNodes(leaves)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, n := range leaves {
+			emit(n)
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(ready)
+	}()
+
+	return ready
+}
+
+// nodeResult is the final signal of a node executed by Run
+type nodeResult struct {
+	id     int
+	signal NodeSignal
+}
+
+// Run executes work for every node of the graph, concurrently, as soon as all
+// of the node's dependencies have completed (using a Scheduler, so ready nodes
+// are started in order of priority), and returns the final signal of every
+// node that was run: Completed, or Aborted with the error returned by work as
+// its payload. Dependents of an aborted node are not run, while independent
+// nodes continue; a node with the Any dependency mode whose dependencies
+// have all aborted is returned as Aborted with ErrDependenciesFailed. The final signals are also reported (see ReportSignal).
+// If work is nil the node's access procedures are committed in order (see
+// CommitProcedures). A node with a timeout (see SetNodeTimeout) runs with
+// a context that carries the deadline, and is Aborted once it passes without
+// waiting for work to return, so work should honor the context.
+// If the context is cancelled no further nodes are started, and the context's
+// error is returned once the running nodes have returned.
+// NOTE: the graph must not be modified while it is being run.
+func (g *Graph) Run(ctx context.Context, work func(context.Context, DGNode) error) (map[int]NodeSignal, error) {
+	if work == nil {
+		work = CommitProcedures
+	}
+
+	signals := make(map[int]NodeSignal)
+	s := NewScheduler(g)
+	results := make(chan nodeResult)
+
+	running := 0
+	for {
+		for ctx.Err() == nil {
+			n, ok := s.Next()
+			if !ok {
+				break
+			}
+			running++
+			g.ReportSignal(n.ID(), Started)
+			go func(n DGNode) {
+				results <- nodeResult{id: n.ID(), signal: g.runNode(ctx, n, work)}
+			}(n)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		r := <-results
+		running--
+		signals[r.id] = r.signal
+		g.ReportSignal(r.id, r.signal.Value)
+		if r.signal.Value == Completed {
+			s.Complete(r.id)
+			continue
+		}
+		for _, id := range s.Abort(r.id) {
+			signals[id] = AbortSignal(0, fmt.Errorf("Node %d: %w", id, ErrDependenciesFailed))
+			g.ReportSignal(id, Aborted)
+		}
+	}
+
+	return signals, ctx.Err()
+}
+
+// runNode executes work for a node within the node's timeout and returns the
+// node's final signal
+func (g *Graph) runNode(ctx context.Context, n DGNode, work func(context.Context, DGNode) error) NodeSignal {
+	d, ok := g.NodeTimeout(n.ID())
+	if !ok {
+		return g.runWork(ctx, n, work)
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	result := make(chan NodeSignal, 1)
+	go func() {
+		result <- g.runWork(tctx, n, work)
+	}()
+
+	select {
+	case signal := <-result:
+		return signal
+	case <-tctx.Done():
+	}
+
+	if ctx.Err() != nil {
+		// cancelled rather than timed out
+		return <-result
+	}
+	return AbortSignal(0, fmt.Errorf("Node %d timed out after %v: %w", n.ID(), d, context.DeadlineExceeded))
+}
+
+// runWork executes work for a node and returns the node's final signal
+func (g *Graph) runWork(ctx context.Context, n DGNode, work func(context.Context, DGNode) error) (signal NodeSignal) {
+	if g.RecoverPanics {
+		defer func() {
+			if r := recover(); r != nil {
+				signal = NodeSignal{
+					Value:   Aborted,
+					Payload: r,
+				}
+			}
+		}()
+	}
+
+	if err := work(ctx, n); err != nil {
+		return AbortSignal(0, err)
+	}
+	return NodeSignal{Value: Completed}
+}
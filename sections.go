@@ -1,5 +1,10 @@
 package fabric
 
+import (
+	"math/rand"
+	"time"
+)
+
 /*
 	Extensional Lists vs. Intensional Conditions
 
@@ -43,7 +48,7 @@ func NewSubgraph(nlp *NodeList, c CDS) Section {
 		for _, e := range cdsEdges {
 			s := e.GetSource()
 			d := e.GetDestination()
-			if d.ID() == n.ID() && ContainsNode(nodes, s) {
+			if NodeEqual(d, n) && ContainsNode(nodes, s) {
 				edges = append(edges, e)
 			}
 		}
@@ -76,6 +81,131 @@ func (s *Subgraph) UpdateEdgeList(elp *EdgeList) {
 	s.Edges = elp
 }
 
+// NewFrontierSection creates a subgraph of all CDS nodes within 'depth'
+// hops of the start node (following edges in both directions i.e. its local
+// neighborhood) and all edges between them.
+func NewFrontierSection(start Node, depth int, c CDS) *Subgraph {
+	nodes := NodeList{start}
+	frontier := NodeList{start}
+
+	for i := 0; i < depth && len(frontier) > 0; i++ {
+		next := make(NodeList, 0)
+		for _, e := range c.ListEdges() {
+			var n Node
+			if ContainsNode(frontier, e.GetSource()) {
+				n = e.GetDestination()
+			} else if ContainsNode(frontier, e.GetDestination()) {
+				n = e.GetSource()
+			} else {
+				continue
+			}
+
+			if !ContainsNode(nodes, n) {
+				nodes = append(nodes, n)
+				next = append(next, n)
+			}
+		}
+		frontier = next
+	}
+
+	return NewSubgraph(&nodes, c).(*Subgraph)
+}
+
+// PartitionCDS splits a CDS into (at most) n sections of roughly equal size
+// by growing each section breadth-first from an unassigned node (following
+// edges in both directions), so sections are connected wherever the CDS
+// allows it. Every CDS node belongs to exactly one section, and every CDS
+// edge to the section of its source node, so the sections cover the whole
+// CDS and satisfy totality-uniqueness.
+func PartitionCDS(c CDS, n int) []*Subgraph {
+	sections := make([]*Subgraph, 0)
+
+	// nodes listed more than once are only assigned once
+	byID := make(map[int]Node)
+	nodes := make(NodeList, 0)
+	for _, v := range c.ListNodes() {
+		if _, ok := byID[v.ID()]; !ok {
+			byID[v.ID()] = v
+			nodes = append(nodes, v)
+		}
+	}
+	if n <= 0 || len(nodes) == 0 {
+		return sections
+	}
+	if n > len(nodes) {
+		n = len(nodes)
+	}
+
+	neighbors := make(map[int][]int)
+	for _, e := range c.ListEdges() {
+		s, d := e.GetSource().ID(), e.GetDestination().ID()
+		neighbors[s] = append(neighbors[s], d)
+		neighbors[d] = append(neighbors[d], s)
+	}
+
+	owner := make(map[int]int)
+	lists := make([]NodeList, n)
+	next := 0 // index of the next node that may be unassigned
+	for i := 0; i < n; i++ {
+		// spread the remainder over the first sections
+		size := len(nodes) / n
+		if i < len(nodes)%n {
+			size++
+		}
+
+		var queue []int
+		for len(lists[i]) < size {
+			if len(queue) == 0 {
+				// start (or continue the section in) a new region
+				for ; next < len(nodes); next++ {
+					if _, ok := owner[nodes[next].ID()]; !ok {
+						break
+					}
+				}
+				id := nodes[next].ID()
+				owner[id] = i
+				lists[i] = append(lists[i], byID[id])
+				queue = append(queue, id)
+				continue
+			}
+
+			id := queue[0]
+			queue = queue[1:]
+			for _, nb := range neighbors[id] {
+				if _, ok := owner[nb]; ok || len(lists[i]) == size {
+					continue
+				}
+				if v, ok := byID[nb]; ok {
+					owner[nb] = i
+					lists[i] = append(lists[i], v)
+					queue = append(queue, nb)
+				}
+			}
+		}
+	}
+
+	edgeLists := make([]EdgeList, n)
+	for i := range edgeLists {
+		edgeLists[i] = make(EdgeList, 0)
+	}
+	for _, e := range c.ListEdges() {
+		if i, ok := owner[e.GetSource().ID()]; ok {
+			edgeLists[i] = append(edgeLists[i], e)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		nl := lists[i]
+		el := edgeLists[i]
+		sections = append(sections, &Subgraph{
+			Nodes: &nl,
+			Edges: &el,
+		})
+	}
+
+	return sections
+}
+
 /*
 	Branches are all nodes and edges for a particuliar branch
 	(usually of a tree graph)
@@ -109,7 +239,7 @@ func dfs(start Node, nodes NodeList, edges EdgeList, c CDS) (NodeList, EdgeList)
 
 	for _, e := range c.ListEdges() {
 		// for all edges in CDS with node as source
-		if e.GetSource() == start {
+		if NodeEqual(e.GetSource(), start) {
 			if !ContainsEdge(edges, e) {
 				// add edge to branch
 				edges = append(edges, e)
@@ -170,14 +300,14 @@ func partDFS(start, end Node, nodes NodeList, edges EdgeList, c CDS) (NodeList,
 	// add node to partition nodes
 	if !ContainsNode(nodes, start) {
 		nodes = append(nodes, start)
-		if start.ID() == end.ID() {
+		if NodeEqual(start, end) {
 			return nodes, edges
 		}
 	}
 
 	for _, e := range c.ListEdges() {
 		// for all edges in CDS with node as source
-		if e.GetSource() == start {
+		if NodeEqual(e.GetSource(), start) {
 			if !ContainsEdge(edges, e) {
 				// add edge to branch
 				edges = append(edges, e)
@@ -226,7 +356,7 @@ func NewSubset(nlp *NodeList, c CDS) Section {
 	edges := make(EdgeList, 0)
 	for _, n := range nodes {
 		for _, e := range cdsEdges {
-			if e.GetSource() == n || e.GetDestination() == n {
+			if NodeEqual(e.GetSource(), n) || NodeEqual(e.GetDestination(), n) {
 				if !ContainsEdge(edges, e) {
 					edges = append(edges, e)
 				}
@@ -260,6 +390,19 @@ func (s *Subset) UpdateEdgeList(elp *EdgeList) {
 	s.Edges = elp
 }
 
+// SelectSection builds a Subset from all CDS nodes that satisfy a predicate
+// (plus all edges connected to those nodes).
+func SelectSection(c CDS, pred func(Node) bool) *Subset {
+	nodes := make(NodeList, 0)
+	for _, n := range c.ListNodes() {
+		if pred(n) {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return NewSubset(&nodes, c).(*Subset)
+}
+
 /* Disjoints are a collection of arbitrary nodes and arbitrary edges */
 
 // Disjoint ...
@@ -329,3 +472,84 @@ func (d *Disjoint) UpdateNodeList(nlp *NodeList) {
 func (d *Disjoint) UpdateEdgeList(elp *EdgeList) {
 	d.Edges = elp
 }
+
+// Disjointed returns true if two sections share no nodes and no edges (by id)
+func Disjointed(a, b Section) bool {
+	nodes := make(map[int]bool)
+	for _, n := range *a.ListNodes() {
+		nodes[n.ID()] = true
+	}
+	for _, n := range *b.ListNodes() {
+		if nodes[n.ID()] {
+			return false
+		}
+	}
+
+	edges := make(map[int]bool)
+	for _, e := range *a.ListEdges() {
+		edges[e.ID()] = true
+	}
+	for _, e := range *b.ListEdges() {
+		if edges[e.ID()] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SectionCDS wraps a Section so that it satisfies the CDS interface;
+// this allows sectioning operations to be run recursively (e.g. creating
+// a subgraph of a branch of a partition).
+type SectionCDS struct {
+	Section Section
+}
+
+// SectionToCDS wraps a section's node and edge lists into a CDS
+func SectionToCDS(s Section) CDS {
+	return &SectionCDS{
+		Section: s,
+	}
+}
+
+// GenNodeID can generate an integer id that is unique from all nodes in the section
+func (c *SectionCDS) GenNodeID() int {
+	rand.Seed(time.Now().UnixNano())
+	id := rand.Int()
+	for _, n := range c.ListNodes() {
+		if n.ID() == id {
+			id = c.GenNodeID()
+		}
+	}
+	return id
+}
+
+// GenEdgeID can generate an integer id that is unique from all edges in the section
+func (c *SectionCDS) GenEdgeID() int {
+	rand.Seed(time.Now().UnixNano())
+	id := rand.Int()
+	for _, e := range c.ListEdges() {
+		if e.ID() == id {
+			id = c.GenEdgeID()
+		}
+	}
+	return id
+}
+
+// ListNodes ...
+func (c *SectionCDS) ListNodes() NodeList {
+	nlp := c.Section.ListNodes()
+	if nlp == nil {
+		return NodeList{}
+	}
+	return *nlp
+}
+
+// ListEdges ...
+func (c *SectionCDS) ListEdges() EdgeList {
+	elp := c.Section.ListEdges()
+	if elp == nil {
+		return EdgeList{}
+	}
+	return *elp
+}
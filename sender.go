@@ -0,0 +1,139 @@
+package fabric
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultSendBuckets are the default upper bounds of the blocking time
+// histogram buckets of a SignalSender
+var DefaultSendBuckets = []time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+// Histogram is a distribution of durations; Counts[i] is the number of
+// observations <= Buckets[i] (and > Buckets[i-1]), and the last element of
+// Counts is the number of observations greater than every bucket bound.
+type Histogram struct {
+	Buckets []time.Duration
+	Counts  []uint64
+	Count   uint64
+	Sum     time.Duration
+}
+
+// observe adds a duration to the histogram
+func (h *Histogram) observe(d time.Duration) {
+	i := sort.Search(len(h.Buckets), func(i int) bool {
+		return d <= h.Buckets[i]
+	})
+	h.Counts[i]++
+	h.Count++
+	h.Sum += d
+}
+
+// SignalSender sends signals over signaling channels while tracking, per
+// destination node, how many sends are pending (blocked on the channel) and
+// how long sends block for; i.e. the back-pressure built up by slow dependent
+// nodes. Observe (if set) is called after every send so that the blocking
+// times can be exported to a metrics system as well.
+type SignalSender struct {
+	mu      sync.Mutex
+	pending map[int]int
+	blocked map[int]*Histogram
+
+	Buckets []time.Duration // histogram bucket upper bounds; should not be changed after the first send
+	Observe func(dstID int, blocked time.Duration)
+}
+
+// NewSignalSender creates a SignalSender with the DefaultSendBuckets
+func NewSignalSender() *SignalSender {
+	return &SignalSender{
+		pending: make(map[int]int),
+		blocked: make(map[int]*Histogram),
+		Buckets: DefaultSendBuckets,
+	}
+}
+
+// Send sends a signal on the channel to the node with id dstID, blocking
+// until the signal has been received (or buffered)
+func (s *SignalSender) Send(dstID int, c chan<- NodeSignal, sig NodeSignal) {
+	s.mu.Lock()
+	if s.pending == nil {
+		s.pending = make(map[int]int)
+		s.blocked = make(map[int]*Histogram)
+	}
+	s.pending[dstID]++
+	s.mu.Unlock()
+
+	start := time.Now()
+	c <- sig
+	d := time.Since(start)
+
+	s.mu.Lock()
+	s.pending[dstID]--
+	h, ok := s.blocked[dstID]
+	if !ok {
+		h = &Histogram{
+			Buckets: s.Buckets,
+			Counts:  make([]uint64, len(s.Buckets)+1),
+		}
+		s.blocked[dstID] = h
+	}
+	h.observe(d)
+	s.mu.Unlock()
+
+	if s.Observe != nil {
+		s.Observe(dstID, d)
+	}
+}
+
+// Signal sends a signal to all dependents of a node (i.e. on every channel
+// in its SignalingMap), in order of dependent node id
+func (s *SignalSender) Signal(n DGNode, sig NodeSignal) {
+	sm := n.ListSignalers()
+
+	ids := make([]int, 0, len(sm))
+	for id := range sm {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		s.Send(id, sm[id], sig)
+	}
+}
+
+// PendingSignals returns the number of sends to a node that are currently blocked
+func (s *SignalSender) PendingSignals(dstID int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.pending[dstID]
+}
+
+// BlockingTimes returns a copy of the histogram of the time sends to a node have blocked for
+func (s *SignalSender) BlockingTimes(dstID int) Histogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, ok := s.blocked[dstID]
+	if !ok {
+		return Histogram{
+			Buckets: s.Buckets,
+			Counts:  make([]uint64, len(s.Buckets)+1),
+		}
+	}
+
+	c := *h
+	c.Counts = make([]uint64, len(h.Counts))
+	copy(c.Counts, h.Counts)
+	return c
+}
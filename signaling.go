@@ -0,0 +1,364 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SignalingTopology returns, for every node in the graph, the sorted ids of
+// the nodes it has outgoing signaling channels to (i.e. the keys of its
+// SignalingMap)
+func (g *Graph) SignalingTopology() map[int][]int {
+	top := make(map[int][]int)
+
+	for n := range g.Top {
+		ids := make([]int, 0)
+		for id := range n.ListSignalers() {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		top[n.ID()] = ids
+	}
+
+	return top
+}
+
+// AssertSignalingMatches will return an error describing every difference
+// between the graph's current signaling topology and an expected one
+// (e.g. one captured with SignalingTopology); nodes missing from either
+// topology are considered to have no outgoing channels.
+func (g *Graph) AssertSignalingMatches(expected map[int][]int) error {
+	actual := g.SignalingTopology()
+
+	ids := make(map[int]bool)
+	for id := range actual {
+		ids[id] = true
+	}
+	for id := range expected {
+		ids[id] = true
+	}
+
+	var sorted []int
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Ints(sorted)
+
+	var diffs []string
+	for _, id := range sorted {
+		want := append([]int{}, expected[id]...)
+		sort.Ints(want)
+		got := actual[id]
+
+		if fmt.Sprint(want) != fmt.Sprint(got) {
+			diffs = append(diffs, fmt.Sprintf("node %d: expected channels to %v, got %v", id, want, got))
+		}
+	}
+
+	if len(diffs) > 0 {
+		return fmt.Errorf("Signaling topology does not match: %v", diffs)
+	}
+
+	return nil
+}
+
+// DrainSignals reads every signal currently available on each incoming
+// channel of a SignalsMap without blocking (in order of dependency node id),
+// so that no sender is left blocked on a channel that will no longer be read
+// from, e.g. when a node is shutting down.
+func DrainSignals(sm SignalsMap) []NodeSignal {
+	ids := make([]int, 0, len(sm))
+	for id := range sm {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	signals := make([]NodeSignal, 0)
+	for _, id := range ids {
+	DRAIN:
+		for {
+			select {
+			case s, ok := <-sm[id]:
+				if !ok {
+					break DRAIN
+				}
+				signals = append(signals, s)
+			default:
+				break DRAIN
+			}
+		}
+	}
+
+	return signals
+}
+
+// SignalRoute returns the sequence of node ids (from srcID to dstID) a
+// signal travels through the signaling topology i.e. the channels in each
+// node's SignalingMap rather than the edges in Top, which can differ if the
+// signaling has not been rebuilt after modifying the graph. The route with
+// the fewest channels is returned (preferring lower node ids on ties), and
+// false is returned if no route exists.
+func (g *Graph) SignalRoute(srcID, dstID int) ([]int, bool) {
+	top := g.SignalingTopology()
+	if _, ok := top[srcID]; !ok {
+		return nil, false
+	}
+
+	prev := map[int]int{srcID: srcID}
+	queue := []int{srcID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if id == dstID {
+			route := []int{id}
+			for id != srcID {
+				id = prev[id]
+				route = append([]int{id}, route...)
+			}
+			return route, true
+		}
+
+		for _, next := range top[id] {
+			if _, seen := prev[next]; !seen {
+				prev[next] = id
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// SignalingHotspots returns the sorted ids of every node that receives
+// signals over more than threshold unbuffered incoming channels (i.e. the
+// channels in its SignalsMap). Every send to such a node blocks until the
+// node reads it, which serializes all of its dependencies; buffering those
+// channels is recommended.
+func (g *Graph) SignalingHotspots(threshold int) []int {
+	ids := make([]int, 0)
+
+	for n := range g.Top {
+		unbuffered := 0
+		for _, c := range n.ListSignals() {
+			if cap(c) == 0 {
+				unbuffered++
+			}
+		}
+
+		if unbuffered > threshold {
+			ids = append(ids, n.ID())
+		}
+	}
+
+	sort.Ints(ids)
+	return ids
+}
+
+// AsymmetricChannel is a half-wired signaling relationship: a channel From
+// a dependency To a dependent that only exists at one end. MissingEnd is
+// "incoming" if the dependent's SignalsMap does not have the dependency's
+// outgoing channel, or "outgoing" if the dependency's SignalingMap does not
+// have the dependent's incoming channel.
+type AsymmetricChannel struct {
+	From       int
+	To         int
+	MissingEnd string
+}
+
+// AsymmetricChannels returns every half-wired signaling relationship in the
+// graph (sorted by From then To id) e.g. after signaling maps have been
+// modified by hand; signals sent over such a channel are never received.
+func (g *Graph) AsymmetricChannels() []AsymmetricChannel {
+	keys := g.nodesByID()
+	asymmetric := make([]AsymmetricChannel, 0)
+
+	for from, n := range keys {
+		for to, c := range n.ListSignalers() {
+			dependent, ok := keys[to]
+			if !ok || dependent.ListSignals()[from] != c {
+				asymmetric = append(asymmetric, AsymmetricChannel{From: from, To: to, MissingEnd: "incoming"})
+			}
+		}
+	}
+
+	for to, n := range keys {
+		for from := range n.ListSignals() {
+			// mismatched channels were reported as missing incoming ends above
+			dependency, ok := keys[from]
+			if ok {
+				_, ok = dependency.ListSignalers()[to]
+			}
+			if !ok {
+				asymmetric = append(asymmetric, AsymmetricChannel{From: from, To: to, MissingEnd: "outgoing"})
+			}
+		}
+	}
+
+	sort.Slice(asymmetric, func(i, j int) bool {
+		if asymmetric[i].From != asymmetric[j].From {
+			return asymmetric[i].From < asymmetric[j].From
+		}
+		return asymmetric[i].To < asymmetric[j].To
+	})
+
+	return asymmetric
+}
+
+// SignalTier sends a signal to every node whose priority is at least
+// minPriority over each of its incoming signaling channels (i.e. the sending
+// end of the channel in each of its dependencies' SignalingMaps), in order
+// of descending priority (then node id), and returns the number of signals
+// sent. This makes it possible to e.g. shut down critical nodes before
+// lower priority ones. Nodes without dependencies have no incoming channels
+// and can not be signaled.
+// NOTE: sends block until received, as with any other signal.
+func (g *Graph) SignalTier(minPriority int, sig NodeSignal) (sent int) {
+	var tier []DGNode
+	for n := range g.Top {
+		if n.GetPriority() >= minPriority {
+			tier = append(tier, n)
+		}
+	}
+	sort.Slice(tier, func(i, j int) bool {
+		if tier[i].GetPriority() != tier[j].GetPriority() {
+			return tier[i].GetPriority() > tier[j].GetPriority()
+		}
+		return tier[i].ID() < tier[j].ID()
+	})
+
+	for _, n := range tier {
+		for _, d := range g.dependencyIDs(n.ID()) {
+			dependency, ok := g.GetNode(d)
+			if !ok {
+				continue
+			}
+			if c, ok := dependency.ListSignalers()[n.ID()]; ok {
+				c <- sig
+				sent++
+			}
+		}
+	}
+
+	return sent
+}
+
+// closeSignal closes a signaling channel, ignoring channels that have
+// already been closed (e.g. by a node when it finished executing)
+func closeSignal(c chan NodeSignal) {
+	defer func() {
+		recover()
+	}()
+	close(c)
+}
+
+// AwaitDependencies waits for a signal from every dependency in a SignalsMap
+// and returns the signals by dependency id, along with the sorted ids of the
+// dependencies whose channel was closed (e.g. because the dependency was
+// removed from the graph, see RemoveRealNode) instead of blocking on them
+// forever. It will return the context's error if the context is done first.
+func AwaitDependencies(ctx context.Context, sm SignalsMap) (map[int]NodeSignal, []int, error) {
+	signals := make(map[int]NodeSignal)
+	removed := make([]int, 0)
+
+	// channels are captured up front as removing a dependency also removes
+	// its channel from the map
+	channels := make(map[int]<-chan NodeSignal, len(sm))
+	ids := make([]int, 0, len(sm))
+	for id, c := range sm {
+		channels[id] = c
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		select {
+		case s, ok := <-channels[id]:
+			if !ok {
+				removed = append(removed, id)
+				continue
+			}
+			signals[id] = s
+		case <-ctx.Done():
+			return signals, removed, ctx.Err()
+		}
+	}
+
+	return signals, removed, nil
+}
+
+// Acknowledge should be called by a receiver, with its own node id, once
+// it has processed a signal; it does nothing if the signal does not expect
+// an acknowledgement (see SignalAwaitAck).
+func (s NodeSignal) Acknowledge(id int) {
+	if s.Ack == nil {
+		return
+	}
+	select {
+	case s.Ack <- id:
+	default:
+	}
+}
+
+// SignalAwaitAck sends a signal from a node to all of its dependents and
+// waits up to timeout for each of them to acknowledge it (see
+// Acknowledge), returning the sorted ids of the dependents that did
+// (acked) and of those that did not receive or acknowledge the signal in
+// time (missed); e.g. to implement a barrier. The signal's Ack channel is
+// replaced with one created for this broadcast.
+func (g *Graph) SignalAwaitAck(srcID int, sig NodeSignal, timeout time.Duration) (acked, missed []int) {
+	acked = make([]int, 0)
+	missed = make([]int, 0)
+
+	n, ok := g.GetNode(srcID)
+	if !ok {
+		return acked, missed
+	}
+
+	signalers := n.ListSignalers()
+	pending := make(map[int]bool)
+	for _, d := range g.dependentIDs(srcID) {
+		if _, ok := signalers[d]; ok {
+			pending[d] = true
+		}
+	}
+
+	// buffered so that late acknowledgements never block receivers
+	sig.Ack = make(chan int, len(pending))
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	stop := make(chan struct{})
+	defer close(stop)
+
+	for d := range pending {
+		go func(c chan NodeSignal) {
+			select {
+			case c <- sig:
+			case <-stop:
+			}
+		}(signalers[d])
+	}
+
+WAIT:
+	for len(pending) > 0 {
+		select {
+		case id := <-sig.Ack:
+			if pending[id] {
+				delete(pending, id)
+				acked = append(acked, id)
+			}
+		case <-deadline.C:
+			break WAIT
+		}
+	}
+
+	for id := range pending {
+		missed = append(missed, id)
+	}
+	sort.Ints(acked)
+	sort.Ints(missed)
+
+	return acked, missed
+}
@@ -0,0 +1,64 @@
+package fabric
+
+import "fmt"
+
+// strictNode asserts the contracts a node must satisfy (but that are not
+// enforced by the DGNode and UI interfaces) before it is added to the graph
+func (g *Graph) strictNode(node DGNode) error {
+	if err := checkHashable(node); err != nil {
+		return fmt.Errorf("Node %d: %v", node.ID(), err)
+	}
+
+	if n, ok := g.GetNode(node.ID()); ok && n != node {
+		return fmt.Errorf("Node %d does not have a unique id: it is already used by another node in the graph", node.ID())
+	}
+
+	if node.ListSignalers() == nil {
+		return fmt.Errorf("Node %d returned a nil SignalingMap from ListSignalers", node.ID())
+	}
+	if node.ListSignals() == nil {
+		return fmt.Errorf("Node %d returned a nil SignalsMap from ListSignals", node.ID())
+	}
+
+	switch node.GetType() {
+	case UINode, VUINode:
+		u, ok := node.(UI)
+		if !ok {
+			return fmt.Errorf("Node %d has type %v but does not satisfy the UI interface", node.ID(), node.GetType())
+		}
+		if u.GetSection() == nil {
+			return fmt.Errorf("UI node %d returned a nil Section from GetSection", node.ID())
+		}
+		if u.IsVirtual() != (node.GetType() == VUINode) {
+			return fmt.Errorf("UI node %d has type %v but IsVirtual returned %v", node.ID(), node.GetType(), u.IsVirtual())
+		}
+	}
+
+	return nil
+}
+
+// strictEdge asserts that both nodes of an edge are present in the graph
+func (g *Graph) strictEdge(source int, dest DGNode) error {
+	if _, ok := g.GetNode(source); !ok {
+		return fmt.Errorf("Source node %d of edge %d -> %d is not present in graph", source, source, dest.ID())
+	}
+	if _, ok := g.GetNode(dest.ID()); !ok {
+		return fmt.Errorf("Destination node %d of edge %d -> %d is not present in graph", dest.ID(), source, dest.ID())
+	}
+
+	return nil
+}
+
+// strictRemove asserts that no node still depends on a node that is being removed
+func (g *Graph) strictRemove(n DGNode) error {
+	if deps := g.Dependents(n); len(deps) != 0 {
+		sortNodes(deps)
+		ids := make([]int, len(deps))
+		for i, d := range deps {
+			ids[i] = d.ID()
+		}
+		return fmt.Errorf("Node %d is still a dependency of nodes %v; removing it would leave their edges dangling", n.ID(), ids)
+	}
+
+	return nil
+}
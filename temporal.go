@@ -1,5 +1,7 @@
 package fabric
 
+import "fmt"
+
 // Temporal is what is assigned to Threads beyond the first thread
 // assigned to a particular UI.
 type Temporal interface {
@@ -28,3 +30,124 @@ type Temporal interface {
 		to by our dependent node.
 
 */
+
+// UIProxy stands in for a UI node of another graph (see BuildTemporalDAG):
+// it has the UI's id, type, section etc. but signaling channels of its own,
+// so that wiring it into a graph leaves the UI untouched in its own graph.
+type UIProxy struct {
+	UI
+	Signalers SignalingMap
+	Signals   SignalsMap
+}
+
+// NewUIProxy creates a proxy for a UI node with no signaling channels
+func NewUIProxy(ui UI) *UIProxy {
+	return &UIProxy{
+		UI:        ui,
+		Signalers: make(SignalingMap),
+		Signals:   make(SignalsMap),
+	}
+}
+
+// UpdateSignaling ...
+func (p *UIProxy) UpdateSignaling(sm SignalingMap, s SignalsMap) {
+	p.Signalers = sm
+	p.Signals = s
+}
+
+// ListSignalers ...
+func (p *UIProxy) ListSignalers() SignalingMap {
+	return p.Signalers
+}
+
+// ListSignals ...
+func (p *UIProxy) ListSignals() SignalsMap {
+	return p.Signals
+}
+
+// Signal ...
+func (p *UIProxy) Signal(s NodeSignal) {
+	for _, c := range p.Signalers {
+		c <- s
+	}
+}
+
+// BuildTemporalDAG generates a temporal DAG for a UI node of the graph: the
+// returned graph contains a proxy of the UI node (see UIProxy) plus one
+// temporal node (created with factory) for each CDS node in the UI's
+// section, in section order. The temporal node accessing a CDS node depends
+// on the temporal node accessing the source of each section edge pointing to
+// it, and temporal nodes without such edges depend on the UI proxy; section
+// edges that would create a cycle (e.g. in a ring) are skipped. The temporal
+// node ids are unique in both graphs (see GenID). The graph itself is not
+// modified; the UI's thread signals its temporal dependents via the proxy.
+func (g *Graph) BuildTemporalDAG(uiID int, factory func(id int) Temporal) (*Graph, error) {
+	n, ok := g.GetNode(uiID)
+	if !ok {
+		return nil, fmt.Errorf("Node %d: %w", uiID, ErrNodeNotFound)
+	}
+	ui, ok := n.(UI)
+	if !ok {
+		return nil, fmt.Errorf("Not a UI node")
+	}
+	section := ui.GetSection()
+	if section == nil {
+		return nil, fmt.Errorf("UI node %d does not have a section", uiID)
+	}
+
+	dag := NewGraph()
+	if ds, ok := g.CDS(); ok {
+		dag.SetCDS(ds)
+	}
+	root, err := dag.AddRealNode(NewUIProxy(ui))
+	if err != nil {
+		return nil, err
+	}
+
+	// temporal node accessing each CDS node
+	access := make(map[int]DGNode)
+	var order []int
+	for _, c := range *section.ListNodes() {
+		if _, ok := access[c.ID()]; ok {
+			continue
+		}
+
+		id := g.GenID()
+		for _, exists := dag.GetNode(id); exists; _, exists = dag.GetNode(id) {
+			id = g.GenID()
+		}
+
+		t := factory(id)
+		if t == nil || t.ID() != id {
+			return nil, fmt.Errorf("Temporal node factory did not return a node with ID %d", id)
+		}
+		added, err := dag.AddRealNode(t)
+		if err != nil {
+			return nil, err
+		}
+		access[c.ID()] = added
+		order = append(order, c.ID())
+	}
+
+	ordered := make(map[int]bool)
+	for _, e := range *section.ListEdges() {
+		src, ok := access[e.GetSource().ID()]
+		if !ok {
+			continue
+		}
+		dst, ok := access[e.GetDestination().ID()]
+		if !ok || dag.WouldCycle(dst.ID(), src.ID()) {
+			continue
+		}
+		dag.addRealEdge(dst.ID(), src)
+		ordered[e.GetDestination().ID()] = true
+	}
+
+	for _, c := range order {
+		if !ordered[c] {
+			dag.addRealEdge(access[c].ID(), root)
+		}
+	}
+
+	return dag, nil
+}
@@ -0,0 +1,178 @@
+// +build test
+
+package fabric_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/JKhawaja/fabric"
+)
+
+// Procedure satisfies the fabric.AccessType interface
+type Procedure struct {
+	Id   int
+	Prty int
+}
+
+func (p Procedure) ID() int {
+	return p.Id
+}
+
+func (p Procedure) Priority() int {
+	return p.Prty
+}
+
+func (p Procedure) Commit(n fabric.DGNode) error {
+	return nil
+}
+
+func (p Procedure) Rollback(rn fabric.RestoreNodes, re fabric.RestoreEdges) error {
+	return nil
+}
+
+func TestAggregatePriority(t *testing.T) {
+	pl := fabric.ProcedureList{Procedure{1, 3}, Procedure{2, 7}, Procedure{3, 2}}
+
+	if p := fabric.AggregatePriority(pl, fabric.MaxPriority); p != 7 {
+		t.Fatalf("Incorrect max priority: %d", p)
+	}
+	if p := fabric.AggregatePriority(pl, fabric.SumPriority); p != 12 {
+		t.Fatalf("Incorrect sum priority: %d", p)
+	}
+	if p := fabric.AggregatePriority(pl, fabric.CountPriority); p != 3 {
+		t.Fatalf("Incorrect count priority: %d", p)
+	}
+}
+
+func TestProcedureGraph(t *testing.T) {
+	pl := fabric.ProcedureList{Procedure{1, 0}, Procedure{2, 0}, Procedure{3, 0}}
+	pg := fabric.NewProcedureGraph(pl)
+
+	// 1 must run after 3, and 3 after 2
+	if err := pg.AddOrder(3, 1); err != nil {
+		t.Fatalf("Could not add procedure order: %v", err)
+	}
+	if err := pg.AddOrder(2, 3); err != nil {
+		t.Fatalf("Could not add procedure order: %v", err)
+	}
+	if err := pg.AddOrder(2, 4); err == nil {
+		t.Fatal("Added ordering for an unknown procedure")
+	}
+
+	order, err := pg.TopoSort()
+	if err != nil {
+		t.Fatalf("Could not order procedures: %v", err)
+	}
+	if order[0].ID() != 2 || order[1].ID() != 3 || order[2].ID() != 1 {
+		t.Fatalf("Incorrect procedure order: %v", order)
+	}
+
+	pg.AddOrder(1, 2)
+	if _, err := pg.TopoSort(); err == nil {
+		t.Fatal("Ordered cyclic procedure constraints")
+	}
+
+	// nodes without a procedure order use list order
+	u := newTestUI(1)
+	*u.AccessProcedures = pl
+	order, _ = fabric.ProcedureOrder(u)
+	if len(order) != 3 || order[0].ID() != 1 {
+		t.Fatalf("Incorrect default procedure order: %v", order)
+	}
+}
+
+// DeleteProcedure is a Procedure that deletes a CDS node
+type DeleteProcedure struct {
+	Procedure
+	Target int
+}
+
+func (p DeleteProcedure) Validate(c fabric.CDS) error {
+	for _, n := range c.ListNodes() {
+		if n.ID() == p.Target {
+			return nil
+		}
+	}
+	return fmt.Errorf("CDS node %d does not exist", p.Target)
+}
+
+func TestPrecheck(t *testing.T) {
+	list := newTestList(2)
+	graph, nodes := chainGraph(t, []int{1, 2}, nil)
+
+	*nodes[1].(UI).AccessProcedures = fabric.ProcedureList{
+		Procedure{1, 0},
+		DeleteProcedure{Procedure{2, 0}, list.Nodes[1].ID()},
+	}
+	*nodes[2].(UI).AccessProcedures = fabric.ProcedureList{
+		DeleteProcedure{Procedure{3, 0}, -1},
+	}
+
+	errs := graph.Precheck(*list)
+	if len(errs) != 1 {
+		t.Fatalf("Incorrect precheck failures: %v", errs)
+	}
+}
+
+// SlowProcedure is a Procedure that takes a while to commit and reports a fixed progress
+type SlowProcedure struct {
+	Procedure
+	Delay time.Duration
+	Done  float64
+}
+
+func (p SlowProcedure) Commit(n fabric.DGNode) error {
+	time.Sleep(p.Delay)
+	return nil
+}
+
+func (p SlowProcedure) Progress() float64 {
+	return p.Done
+}
+
+func TestWithTimeout(t *testing.T) {
+	// 2 depends on 1
+	_, nodes := chainGraph(t, []int{1, 2}, [][2]int{{2, 1}})
+	c := make(chan fabric.NodeSignal, 1)
+	nodes[1].ListSignalers()[2] = c
+
+	fast := fabric.WithTimeout(SlowProcedure{Procedure{1, 0}, 0, 0}, time.Second)
+	if err := fast.Commit(nodes[1]); err != nil || len(c) != 0 {
+		t.Fatalf("Procedure within its timeout failed: %v", err)
+	}
+
+	for _, done := range []float64{0, 0.5} {
+		slow := fabric.WithTimeout(SlowProcedure{Procedure{2, 0}, time.Second, done}, 10*time.Millisecond)
+		if err := slow.Commit(nodes[1]); err == nil {
+			t.Fatal("Procedure did not time out")
+		}
+
+		want := fabric.Aborted
+		if done > 0 {
+			want = fabric.PartialAbort
+		}
+		if s := <-c; s.Value != want || s.AccessType != 2 {
+			t.Fatalf("Incorrect timeout signal: %v", s)
+		}
+	}
+
+	// timeouts are reported without waiting for the dependent to receive the
+	// signal (over an unbuffered channel)
+	_, nodes = chainGraph(t, []int{1, 2}, [][2]int{{2, 1}})
+	slow := fabric.WithTimeout(SlowProcedure{Procedure{3, 0}, time.Second, 0}, 10*time.Millisecond)
+	if err := slow.Commit(nodes[1]); err == nil {
+		t.Fatal("Procedure did not time out")
+	}
+	if s := <-nodes[2].ListSignals()[1]; s.Value != fabric.Aborted || s.AccessType != 3 {
+		t.Fatalf("Incorrect timeout signal: %v", s)
+	}
+
+	// wrapped procedures are still validated
+	list := newTestList(1)
+	v, ok := fabric.WithTimeout(DeleteProcedure{Procedure{4, 0}, -1}, time.Second).(fabric.Validator)
+	if !ok || v.Validate(*list) == nil {
+		t.Fatal("Wrapped procedure was not validated")
+	}
+}
@@ -0,0 +1,588 @@
+// +build test
+
+package fabric_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/JKhawaja/fabric"
+)
+
+// newTestUI creates a (non-virtual) UI node with an id and empty signaling maps
+func newTestUI(id int) UI {
+	sm := make(fabric.SignalingMap)
+	s := make(fabric.SignalsMap)
+	p := make(fabric.ProcedureList, 0)
+	return UI{
+		Node: Node{
+			Id:               id,
+			Type:             fabric.UINode,
+			Signalers:        &sm,
+			Signals:          &s,
+			AccessProcedures: &p,
+		},
+	}
+}
+
+// chainGraph builds a graph containing UI nodes with the given ids and
+// the given (source, destination) edges between them
+func chainGraph(t *testing.T, ids []int, edges [][2]int) (*fabric.Graph, map[int]fabric.DGNode) {
+	graph := fabric.NewGraph()
+	nodes := make(map[int]fabric.DGNode)
+
+	for _, id := range ids {
+		n, err := graph.AddRealNode(newTestUI(id))
+		if err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+		nodes[id] = n
+	}
+
+	for _, e := range edges {
+		graph.AddRealEdge(e[0], nodes[e[1]])
+	}
+
+	return graph, nodes
+}
+
+func TestUnreachableFromRoots(t *testing.T) {
+	// 1 -> 2 -> 3 is reachable from root 1; 4 <-> 5 is a cycle with no entry point
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{1, 2}, {2, 3}, {4, 5}, {5, 4}})
+
+	roots := graph.Roots()
+	if len(roots) != 1 || roots[0].ID() != 1 {
+		t.Fatalf("Incorrect root boundary nodes: %v", roots)
+	}
+
+	unreachable := graph.UnreachableFromRoots()
+	if len(unreachable) != 2 || unreachable[0].ID() != 4 || unreachable[1].ID() != 5 {
+		t.Fatalf("Incorrect unreachable nodes: %v", unreachable)
+	}
+}
+
+func TestAdjacencyMatrix(t *testing.T) {
+	graph, _ := chainGraph(t, []int{3, 1, 2}, [][2]int{{1, 2}, {2, 3}, {1, 3}})
+
+	matrix, ids := graph.AdjacencyMatrix()
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 3 {
+		t.Fatalf("Incorrect matrix ordering: %v", ids)
+	}
+
+	expected := [][]bool{
+		{false, true, true},
+		{false, false, true},
+		{false, false, false},
+	}
+	for i := range expected {
+		for j := range expected[i] {
+			if matrix[i][j] != expected[i][j] {
+				t.Fatalf("Incorrect matrix entry [%d][%d]: %v", i, j, matrix[i][j])
+			}
+		}
+	}
+}
+
+func TestRedundantEdges(t *testing.T) {
+	// 1 -> 3 is implied by 1 -> 2 -> 3
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{1, 2}, {2, 3}, {1, 3}, {3, 4}, {2, 4}})
+
+	redundant := graph.RedundantEdges()
+	if len(redundant) != 2 || redundant[0] != [2]int{1, 3} || redundant[1] != [2]int{2, 4} {
+		t.Fatalf("Incorrect redundant edges: %v", redundant)
+	}
+
+	// original graph is intact
+	if len(graph.Dependencies(graph.Roots()[0])) != 2 {
+		t.Fatal("RedundantEdges modified the graph")
+	}
+}
+
+func TestDanglingEdges(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1}, nil)
+
+	vu := newTestUI(2)
+	vu.Virtual = true
+	vp, err := graph.AddVUI(vu)
+	if err != nil {
+		t.Fatalf("Could not add VUI node to graph: %v", err)
+	}
+	graph.AddRealEdge(1, vp)
+
+	if len(graph.DanglingEdges()) != 0 {
+		t.Fatalf("Incorrectly detected dangling edges: %v", graph.DanglingEdges())
+	}
+
+	// removing the VUI leaves the edge from its dependent behind
+	err = graph.RemoveVUI(vp)
+	if err != nil {
+		t.Fatalf("Could not remove VUI node from graph: %v", err)
+	}
+
+	dangling := graph.DanglingEdges()
+	if len(dangling) != 1 || dangling[0] != [2]int{1, 2} {
+		t.Fatalf("Incorrect dangling edges: %v", dangling)
+	}
+}
+
+func TestString(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{1, 2}, {1, 3}, {2, 3}, {4, 5}, {5, 4}})
+
+	expected := "1 [UI]\n" +
+		"  2 [UI]\n" +
+		"    3 [UI]\n" +
+		"  3 [UI]\n" +
+		"4 [UI]\n" +
+		"  5 [UI]\n" +
+		"    (cycle→4)\n"
+	if graph.String() != expected {
+		t.Fatalf("Incorrect graph string:\n%s", graph.String())
+	}
+}
+
+func TestWriteDOTWithState(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}, {2, 3}})
+
+	var buf bytes.Buffer
+	state := map[int]fabric.Signal{2: fabric.Started, 3: fabric.Completed}
+	if err := graph.WriteDOTWithState(&buf, state); err != nil {
+		t.Fatalf("Could not write DOT: %v", err)
+	}
+
+	expected := "digraph fabric {\n" +
+		"\t1 [label=\"1 [UI]\"];\n" +
+		"\t2 [label=\"2 [UI]\", style=filled, fillcolor=yellow];\n" +
+		"\t3 [label=\"3 [UI]\", style=filled, fillcolor=green];\n" +
+		"\t1 -> 2;\n" +
+		"\t2 -> 3;\n" +
+		"}\n"
+	if buf.String() != expected {
+		t.Fatalf("Incorrect DOT output:\n%s", buf.String())
+	}
+}
+
+func TestWriteCDSDOT(t *testing.T) {
+	list := newTestList(2)
+
+	var buf bytes.Buffer
+	if err := fabric.WriteCDSDOT(*list, &buf); err != nil {
+		t.Fatalf("Could not write CDS DOT: %v", err)
+	}
+
+	a, b := list.Nodes[0].ID(), list.Nodes[1].ID()
+	first, second := a, b
+	if b < a {
+		first, second = b, a
+	}
+	expected := "digraph cds {\n" +
+		fmt.Sprintf("\t%d [label=\"%d\"];\n", first, first) +
+		fmt.Sprintf("\t%d [label=\"%d\"];\n", second, second) +
+		fmt.Sprintf("\t%d -> %d [label=\"%d\"];\n", a, b, list.Edges[0].ID()) +
+		"}\n"
+	if buf.String() != expected {
+		t.Fatalf("Incorrect CDS DOT output:\n%s", buf.String())
+	}
+}
+
+func TestWriteCoverageDOT(t *testing.T) {
+	list := newTestList(3)
+	n0, n1, n2 := list.Nodes[0].ID(), list.Nodes[1].ID(), list.Nodes[2].ID()
+
+	graph := fabric.NewGraph()
+	var buf bytes.Buffer
+	if err := graph.WriteCoverageDOT(&buf); err == nil {
+		t.Fatal("Wrote coverage without a CDS")
+	}
+	graph.SetCDS(*list)
+
+	// UI 1 covers the first two nodes, UI 2 the second node, nothing the last
+	u1 := newTestUI(1)
+	u1.CDS = fabric.SelectSection(*list, func(n fabric.Node) bool { return n.ID() == n0 || n.ID() == n1 })
+	u2 := newTestUI(2)
+	u2.CDS = fabric.SelectSection(*list, func(n fabric.Node) bool { return n.ID() == n1 })
+	for _, u := range []UI{u1, u2} {
+		if _, err := graph.AddRealNode(u); err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+	}
+
+	if err := graph.WriteCoverageDOT(&buf); err != nil {
+		t.Fatalf("Could not write coverage DOT: %v", err)
+	}
+
+	for _, line := range []string{
+		fmt.Sprintf("\t%d [label=\"%d\", style=filled, fillcolor=\"lightblue\", xlabel=\"UI 1\"];\n", n0, n0),
+		fmt.Sprintf("\t%d [label=\"%d\", style=wedged, fillcolor=\"lightblue:palegreen\", xlabel=\"UI 1,2\"];\n", n1, n1),
+		fmt.Sprintf("\t%d [label=\"%d\", style=filled, fillcolor=red];\n", n2, n2),
+		fmt.Sprintf("\t%d -> %d [label=\"%d\", color=\"lightblue:palegreen\", xlabel=\"UI 1,2\"];\n", n1, n2, list.Edges[1].ID()),
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Fatalf("Coverage DOT output is missing %q:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestDFSOrder(t *testing.T) {
+	// 1 depends on 2 and 3, 2 depends on 4, 3 depends on 4
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{1, 3}, {1, 2}, {2, 4}, {3, 4}})
+
+	collect := func(order fabric.TraversalOrder, limit int) []int {
+		var ids []int
+		graph.DFSOrder(1, order, func(n fabric.DGNode) bool {
+			ids = append(ids, n.ID())
+			return len(ids) < limit
+		})
+		return ids
+	}
+
+	pre := collect(fabric.PreOrder, 4)
+	post := collect(fabric.PostOrder, 4)
+	if fmt.Sprint(pre) != "[1 2 4 3]" {
+		t.Fatalf("Incorrect pre-order traversal: %v", pre)
+	}
+	if fmt.Sprint(post) != "[4 2 3 1]" {
+		t.Fatalf("Incorrect post-order traversal: %v", post)
+	}
+	if stopped := collect(fabric.PostOrder, 2); len(stopped) != 2 {
+		t.Fatalf("Traversal was not stopped: %v", stopped)
+	}
+}
+
+func TestSpine(t *testing.T) {
+	// 5 -> 4 -> 2 -> 1 is the longest path (5 -> 3 -> 1 is shorter)
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{5, 4}, {4, 2}, {2, 1}, {5, 3}, {3, 1}})
+
+	spine, err := graph.Spine()
+	if err != nil {
+		t.Fatalf("Could not compute spine: %v", err)
+	}
+
+	expected := []int{1, 2, 4, 5}
+	if len(spine) != len(expected) {
+		t.Fatalf("Incorrect spine: %v", spine)
+	}
+	for i, n := range spine {
+		if n.ID() != expected[i] {
+			t.Fatalf("Incorrect spine: %v", spine)
+		}
+	}
+
+	graph.AddRealEdge(1, spine[3])
+	if _, err := graph.Spine(); err == nil {
+		t.Fatal("Computed spine of a cyclic graph")
+	}
+}
+
+func TestForEachEdge(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1, 2, 3}, [][2]int{{3, 1}, {1, 3}, {1, 2}, {2, 3}})
+
+	var edges [][2]int
+	graph.ForEachEdge(func(src, dst fabric.DGNode) bool {
+		edges = append(edges, [2]int{src.ID(), dst.ID()})
+		return len(edges) < 3
+	})
+
+	expected := [][2]int{{1, 2}, {1, 3}, {2, 3}}
+	if len(edges) != len(expected) {
+		t.Fatalf("Incorrect edge iteration: %v", edges)
+	}
+	for i := range expected {
+		if edges[i] != expected[i] {
+			t.Fatalf("Incorrect edge iteration: %v", edges)
+		}
+	}
+}
+
+func TestFeedbackEdgeSet(t *testing.T) {
+	// two cycles sharing the edge 3 -> 1
+	graph, nodes := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{1, 2}, {2, 3}, {3, 1}, {1, 4}, {4, 3}})
+
+	feedback := graph.FeedbackEdgeSet()
+	if len(feedback) != 1 || feedback[0] != [2]int{3, 1} {
+		t.Fatalf("Incorrect feedback edge set: %v", feedback)
+	}
+
+	for _, e := range feedback {
+		graph.RemoveRealEdge(e[0], nodes[e[1]])
+	}
+	if graph.CycleDetect() {
+		t.Fatal("Graph still has cycles after removing feedback edges")
+	}
+
+	if len(graph.FeedbackEdgeSet()) != 0 {
+		t.Fatal("Found feedback edges in an acyclic graph")
+	}
+}
+
+func TestTopoStream(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}, {2, 3}})
+
+	stream, errs := graph.TopoStream(context.Background())
+	var order []int
+	for n := range stream {
+		order = append(order, n.ID())
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Could not stream topological order: %v", err)
+	}
+	if len(order) != 3 || order[0] != 3 || order[1] != 2 || order[2] != 1 {
+		t.Fatalf("Incorrect topological order: %v", order)
+	}
+
+	// cyclic graphs send an error
+	graph.AddRealEdge(3, nodes[1])
+	stream, errs = graph.TopoStream(context.Background())
+	for range stream {
+	}
+	if err := <-errs; err == nil {
+		t.Fatal("No error for cyclic graph")
+	}
+}
+
+func TestIncomingOutgoingEdges(t *testing.T) {
+	// 1 and 2 depend on 3, 3 depends on 4
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{2, 3}, {1, 3}, {3, 4}})
+	graph.SetEdgeWeight(1, 3, 2.5)
+	graph.SetEdgeClasses(1, 3, []string{"write"})
+
+	in := graph.IncomingEdges(3)
+	if len(in) != 2 || in[0].Source != 1 || in[1].Source != 2 || in[0].Dest != 3 {
+		t.Fatalf("Incorrect incoming edges: %v", in)
+	}
+	if in[0].Weight != 2.5 || len(in[0].Classes) != 1 || in[1].Weight != 1 {
+		t.Fatalf("Incorrect edge metadata: %v", in)
+	}
+
+	out := graph.OutgoingEdges(3)
+	if len(out) != 1 || out[0].Source != 3 || out[0].Dest != 4 {
+		t.Fatalf("Incorrect outgoing edges: %v", out)
+	}
+}
+
+func TestShortestWeightedPath(t *testing.T) {
+	// 1 -> 2 -> 4 has fewer hops but 1 -> 3 -> 5 -> 4 is lighter
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{1, 2}, {2, 4}, {1, 3}, {3, 5}, {5, 4}})
+	graph.SetEdgeWeight(1, 2, 5)
+	graph.SetEdgeWeight(3, 5, 0.5)
+
+	if err := graph.SetEdgeWeight(1, 4, 1); err == nil {
+		t.Fatal("Set weight of a non-existent edge")
+	}
+	if err := graph.SetEdgeWeight(1, 3, -1); err == nil {
+		t.Fatal("Set a negative edge weight")
+	}
+
+	path, weight, err := graph.ShortestWeightedPath(1, 4)
+	if err != nil {
+		t.Fatalf("Could not find path: %v", err)
+	}
+	if weight != 2.5 || len(path) != 4 || path[1].ID() != 3 || path[2].ID() != 5 {
+		t.Fatalf("Incorrect shortest path: %v (%v)", path, weight)
+	}
+
+	if _, _, err := graph.ShortestWeightedPath(4, 1); err == nil {
+		t.Fatal("Found path to an unreachable node")
+	}
+}
+
+// PriorityUI is a UI node with a configurable priority
+type PriorityUI struct {
+	UI
+	Priority int
+}
+
+func (u PriorityUI) GetPriority() int {
+	return u.Priority
+}
+
+func TestPriorityInversions(t *testing.T) {
+	graph := fabric.NewGraph()
+	nodes := make(map[int]fabric.DGNode)
+	for id, p := range map[int]int{1: 3, 2: 2, 3: 2} {
+		n, err := graph.AddRealNode(PriorityUI{UI: newTestUI(id), Priority: p})
+		if err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+		nodes[id] = n
+	}
+
+	// 1 (priority 3) depends on 2 (priority 2); 2 depends on an equal priority node
+	graph.AddRealEdge(1, nodes[2])
+	graph.AddRealEdge(2, nodes[3])
+	graph.AddRealEdge(3, nodes[1])
+
+	inversions := graph.PriorityInversions()
+	if len(inversions) != 1 || inversions[0] != [2]int{1, 2} {
+		t.Fatalf("Incorrect priority inversions: %v", inversions)
+	}
+}
+
+func TestMinCut(t *testing.T) {
+	// two paths from 1 to 5 (1 -> 2 -> 4 -> 5 and 1 -> 3 -> 4 -> 5) that share the edge 4 -> 5
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4, 5, 6}, [][2]int{{1, 2}, {1, 3}, {2, 4}, {3, 4}, {4, 5}, {1, 6}})
+
+	cut := graph.MinCut(1, 5)
+	if len(cut) != 1 || cut[0] != [2]int{4, 5} {
+		t.Fatalf("Incorrect minimum cut: %v", cut)
+	}
+
+	cut = graph.MinCut(1, 4)
+	if len(cut) != 2 {
+		t.Fatalf("Incorrect minimum cut: %v", cut)
+	}
+
+	if len(graph.MinCut(5, 1)) != 0 {
+		t.Fatal("Cut returned for unreachable node")
+	}
+}
+
+func TestIsomorphic(t *testing.T) {
+	a, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{1, 2}, {1, 3}, {2, 4}, {3, 4}})
+	b, _ := chainGraph(t, []int{10, 20, 30, 40}, [][2]int{{40, 30}, {40, 10}, {30, 20}, {10, 20}})
+	if !fabric.Isomorphic(a, b) {
+		t.Fatal("Structurally identical graphs are not isomorphic")
+	}
+
+	// same degrees but a different structure
+	c, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{1, 2}, {2, 3}, {3, 4}, {4, 1}})
+	d, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{1, 2}, {2, 1}, {3, 4}, {4, 3}})
+	if fabric.Isomorphic(c, d) {
+		t.Fatal("Structurally different graphs are isomorphic")
+	}
+
+	if fabric.Isomorphic(a, c) {
+		t.Fatal("Graphs with different degrees are isomorphic")
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2}, [][2]int{{1, 2}})
+
+	view := graph.ReadOnly()
+	if _, ok := view.(*fabric.Graph); ok {
+		t.Fatal("Read-only view can be converted to a graph")
+	}
+	if deps := view.Dependencies(nodes[1]); len(deps) != 1 || deps[0].ID() != 2 {
+		t.Fatalf("Incorrect dependencies from read-only view: %v", deps)
+	}
+	if view.Stats().Edges != 1 || view.String() != graph.String() {
+		t.Fatal("Read-only view does not match graph")
+	}
+}
+
+func TestBetweennessCentrality(t *testing.T) {
+	// 1 -> 2 -> 4 and 1 -> 3 -> 4; 4 -> 5
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{1, 2}, {1, 3}, {2, 4}, {3, 4}, {4, 5}})
+
+	cb := graph.BetweennessCentrality()
+	// 2 and 3 each carry half of the paths 1 -> 4 and 1 -> 5
+	if cb[2] != 1 || cb[3] != 1 {
+		t.Fatalf("Incorrect betweenness of relays: %v", cb)
+	}
+	// 4 carries every path to 5 (from 1, 2 and 3)
+	if cb[4] != 3 || cb[1] != 0 || cb[5] != 0 {
+		t.Fatalf("Incorrect betweenness: %v", cb)
+	}
+}
+
+func TestFanOutStats(t *testing.T) {
+	// 1 depends on 2, 3, 4 and 5; 2 depends on 3
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{1, 2}, {1, 3}, {1, 4}, {1, 5}, {2, 3}})
+
+	min, max, mean, stddev := graph.FanOutStats()
+	if min != 0 || max != 4 || mean != 1 || math.Abs(stddev-math.Sqrt(2.4)) > 1e-9 {
+		t.Fatalf("Incorrect fan-out stats: %v, %v, %v, %v", min, max, mean, stddev)
+	}
+
+	if skewed := graph.SkewedNodes(1); fmt.Sprint(skewed) != "[1]" {
+		t.Fatalf("Incorrect skewed nodes: %v", skewed)
+	}
+	if skewed := graph.SkewedNodes(3); len(skewed) != 0 {
+		t.Fatalf("Incorrect skewed nodes: %v", skewed)
+	}
+}
+
+func TestHash(t *testing.T) {
+	a, _ := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}, {2, 3}})
+	b, _ := chainGraph(t, []int{3, 2, 1}, [][2]int{{2, 3}, {1, 2}})
+	if a.Hash() != b.Hash() {
+		t.Fatal("Identical graphs hash differently")
+	}
+
+	c, _ := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}, {1, 3}})
+	if a.Hash() == c.Hash() {
+		t.Fatal("Different graphs hash equal")
+	}
+}
+
+func TestIsTree(t *testing.T) {
+	// 2 and 3 depend on 1, 4 depends on 2
+	tree, treeNodes := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{2, 1}, {3, 1}, {4, 2}})
+	if !tree.IsTree() || !tree.IsForest() {
+		t.Fatal("Tree was not detected")
+	}
+
+	forest, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{2, 1}, {4, 3}})
+	if forest.IsTree() || !forest.IsForest() {
+		t.Fatal("Forest was not detected")
+	}
+
+	// 4 depends on 2 and 3
+	tree.AddRealEdge(4, treeNodes[3])
+	if tree.IsTree() || tree.IsForest() {
+		t.Fatal("Node with several dependencies was allowed in a tree")
+	}
+
+	cycle, _ := chainGraph(t, []int{1, 2}, [][2]int{{1, 2}, {2, 1}})
+	if cycle.IsForest() {
+		t.Fatal("Cycle was allowed in a forest")
+	}
+}
+
+func TestTopoSortFrom(t *testing.T) {
+	// 3 depends on 1 and 2, 4 depends on 3
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{3, 1}, {3, 2}, {4, 3}})
+
+	order, err := graph.TopoSortFrom(map[int]bool{1: true, 2: true, 4: false})
+	if err != nil {
+		t.Fatalf("Could not sort graph: %v", err)
+	}
+	var ids []int
+	for _, n := range order {
+		ids = append(ids, n.ID())
+	}
+	if fmt.Sprint(ids) != "[3 4]" {
+		t.Fatalf("Incorrect resumed order: %v", ids)
+	}
+}
+
+func TestCollapseChains(t *testing.T) {
+	// 1 -> 2 -> 3 -> 4 -> 6 -> 7, and 5 -> 4
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4, 5, 6, 7}, [][2]int{{1, 2}, {2, 3}, {3, 4}, {5, 4}, {4, 6}, {6, 7}})
+
+	collapsed := graph.CollapseChains()
+	if len(collapsed.Top) != 3 || len(graph.Top) != 7 {
+		t.Fatalf("Incorrect number of collapsed nodes: %d", len(collapsed.Top))
+	}
+
+	chains := make(map[int]string)
+	for n := range collapsed.Top {
+		chains[n.ID()] = fmt.Sprint(n.(*fabric.ChainNode).Nodes)
+	}
+	if chains[1] != "[1 2 3]" || chains[4] != "[4 6 7]" || chains[5] != "[5]" {
+		t.Fatalf("Incorrect chains: %v", chains)
+	}
+
+	edges := make(map[string]bool)
+	collapsed.ForEachEdge(func(src, dst fabric.DGNode) bool {
+		edges[fmt.Sprintf("%d->%d", src.ID(), dst.ID())] = true
+		return true
+	})
+	if len(edges) != 2 || !edges["1->4"] || !edges["5->4"] {
+		t.Fatalf("Incorrect collapsed edges: %v", edges)
+	}
+}
@@ -3,6 +3,9 @@
 package fabric_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math/rand"
 	"testing"
 	"time"
@@ -129,6 +132,10 @@ func (t Temporal) IsVirtual() bool {
 	return t.Virtual
 }
 
+func (t Temporal) GetRoots() []fabric.UI {
+	return []fabric.UI{t.UIRoot}
+}
+
 // TestDG: tests adding nodes and edges, leaf and root boundary checks,
 // and Signalers and Signals checks as well
 func TestDG(t *testing.T) {
@@ -595,7 +602,832 @@ func TestCovered(t *testing.T) {
 	}
 
 	// check that a CDS covered verification fails
-	if graph.Covered() {
+	if covered, err := graph.Covered(); err != nil || covered {
 		t.Fatal("Incorrectly classified graph as covering entire CDS")
 	}
 }
+
+// MapUI is a UI whose concrete type embeds a map (and therefore is not comparable)
+type MapUI struct {
+	UI
+	Tags map[string]string
+}
+
+func TestAddVUI(t *testing.T) {
+	graph := fabric.NewGraph()
+
+	// a non-comparable VUI value is rejected instead of panicking
+	vu := newTestUI(1)
+	vu.Virtual = true
+	_, err := graph.AddVUI(MapUI{UI: vu, Tags: make(map[string]string)})
+	if err == nil {
+		t.Fatal("Non-comparable VUI was added to graph")
+	}
+
+	// a pointer to the same type can be used
+	vp, err := graph.AddVUI(&MapUI{UI: vu, Tags: make(map[string]string)})
+	if err != nil {
+		t.Fatalf("Could not add VUI node to graph: %v", err)
+	}
+	if n, ok := graph.GetNode(1); !ok || n != vp {
+		t.Fatal("VUI node was not indexed")
+	}
+
+	// a different node value with an existing id is rejected
+	_, err = graph.AddVUI(vu)
+	if err == nil {
+		t.Fatal("VUI with a duplicate id was added to graph")
+	}
+}
+
+func TestDependenciesE(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1, 2}, [][2]int{{1, 2}})
+
+	deps, err := graph.DependenciesE(2)
+	if err != nil || len(deps) != 0 {
+		t.Fatalf("Incorrect dependencies for leaf boundary node: %v, %v", deps, err)
+	}
+
+	deps, err = graph.DependenciesE(1)
+	if err != nil || len(deps) != 1 || deps[0].ID() != 2 {
+		t.Fatalf("Incorrect dependencies: %v, %v", deps, err)
+	}
+
+	if _, err := graph.DependenciesE(3); err == nil {
+		t.Fatal("No error returned for unknown node")
+	}
+}
+
+func TestDependentsIndex(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{2, 1}, {3, 1}, {4, 2}})
+
+	if graph.InDegree(nodes[1]) != 2 || graph.InDegree(nodes[4]) != 0 {
+		t.Fatalf("Incorrect in-degrees: %d, %d", graph.InDegree(nodes[1]), graph.InDegree(nodes[4]))
+	}
+
+	desc := graph.Descendants(nodes[1])
+	if len(desc) != 3 || desc[0].ID() != 2 || desc[1].ID() != 3 || desc[2].ID() != 4 {
+		t.Fatalf("Incorrect descendants: %v", desc)
+	}
+
+	// the index picks up new edges
+	graph.AddRealEdge(4, nodes[3])
+	if graph.InDegree(nodes[3]) != 1 {
+		t.Fatal("Dependents index was not updated with new edge")
+	}
+
+	// and direct modification of Top after a Reindex
+	graph.Top[nodes[4]] = []fabric.DGNode{}
+	graph.Reindex()
+	if graph.InDegree(nodes[3]) != 0 || graph.InDegree(nodes[2]) != 0 {
+		t.Fatal("Dependents index was not rebuilt")
+	}
+
+	// removed nodes are dropped from the index, even if dependents keep dangling edges to them
+	vu := newTestUI(5)
+	vu.Virtual = true
+	vp, err := graph.AddVUI(vu)
+	if err != nil {
+		t.Fatalf("Could not add VUI node to graph: %v", err)
+	}
+	graph.AddRealEdge(1, vp)
+	if err := graph.RemoveVUI(vp); err != nil {
+		t.Fatalf("Could not remove VUI node from graph: %v", err)
+	}
+	if graph.InDegree(vp) != 0 {
+		t.Fatal("Removed node was not dropped from the dependents index")
+	}
+	graph.Reindex()
+	if graph.InDegree(vp) != 0 {
+		t.Fatal("Dangling edge was indexed")
+	}
+}
+
+func TestGenerateUIs(t *testing.T) {
+	list := newTestList(10)
+
+	uis := fabric.GenerateUIs(*list, 3, 0.5, 42)
+	if len(uis) != 3 {
+		t.Fatalf("Incorrect number of generated UIs: %d", len(uis))
+	}
+
+	covered := 0
+	for _, u := range uis {
+		covered += len(*u.GetSection().ListNodes())
+	}
+	if covered != 5 {
+		t.Fatalf("Incorrect number of covered CDS nodes: %d", covered)
+	}
+
+	again := fabric.GenerateUIs(*list, 3, 0.5, 42)
+	for i := range uis {
+		if uis[i].ID() != again[i].ID() {
+			t.Fatal("UI generation is not reproducible")
+		}
+	}
+
+	// complete coverage
+	graph := fabric.NewGraph()
+	graph.DS = *list
+	for _, u := range fabric.GenerateUIs(*list, 4, 1, 7) {
+		if _, err := graph.AddRealNode(u); err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+	}
+	if covered, _ := graph.Covered(); !covered {
+		t.Fatal("Generated UIs with full coverage do not cover CDS")
+	}
+}
+
+func TestIsVirtual(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1}, nil)
+
+	vu := newTestUI(2)
+	vu.Virtual = true
+	if _, err := graph.AddVUI(vu); err != nil {
+		t.Fatalf("Could not add VUI node to graph: %v", err)
+	}
+
+	if graph.IsVirtual(1) || !graph.IsVirtual(2) || graph.IsVirtual(3) {
+		t.Fatal("Incorrectly classified virtual nodes")
+	}
+}
+
+func TestCoverageReport(t *testing.T) {
+	list := newTestList(3)
+
+	graph := fabric.NewGraph()
+	graph.DS = *list
+
+	// UI covering the last two nodes and the edge between them
+	last := fabric.NodeList{list.Nodes[1], list.Nodes[2]}
+	u := newTestUI(1)
+	u.CDS = fabric.NewSubgraph(&last, *list)
+	if _, err := graph.AddRealNode(u); err != nil {
+		t.Fatalf("Could not add UI node to graph: %v", err)
+	}
+
+	report := graph.CoverageReport()
+	if report.Covered || len(report.UncoveredNodes) != 1 || len(report.UncoveredEdges) != 1 {
+		t.Fatalf("Incorrect coverage report: %v", report)
+	}
+	if report.UncoveredNodes[0].ID() != list.Nodes[0].ID() {
+		t.Fatal("Incorrect uncovered node")
+	}
+}
+
+func TestStats(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}, {1, 3}})
+
+	stats := graph.Stats()
+	if stats.Nodes != 3 || stats.Edges != 2 || stats.Roots != 1 || stats.Leaves != 2 || stats.Types[fabric.UINode] != 3 {
+		t.Fatalf("Incorrect graph stats: %v", stats)
+	}
+
+	order, err := graph.TopoSort()
+	if err != nil || len(order) != 3 || order[2].ID() != 1 {
+		t.Fatalf("Incorrect topological order: %v, %v", order, err)
+	}
+}
+
+func TestGetAdjacents(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{5, 3}, {1, 3}, {3, 4}, {3, 2}})
+
+	adj := graph.GetAdjacents(nodes[3])
+	expected := []int{1, 5, 2, 4}
+	if len(adj) != len(expected) {
+		t.Fatalf("Incorrect adjacents: %v", adj)
+	}
+	for i, n := range adj {
+		if n.ID() != expected[i] {
+			t.Fatalf("Incorrect adjacents order: %v", adj)
+		}
+	}
+}
+
+// SectionTemporal is a Temporal node with its own section
+type SectionTemporal struct {
+	Temporal
+	CDS fabric.Section
+}
+
+func (t SectionTemporal) GetSection() fabric.Section {
+	return t.CDS
+}
+
+func TestCoveredBy(t *testing.T) {
+	list := newTestList(3)
+
+	graph := fabric.NewGraph()
+	graph.DS = *list
+
+	// UI covers all CDS nodes but no edges
+	nodes := list.ListNodes()
+	edges := make(fabric.EdgeList, 0)
+	u := newTestUI(1)
+	u.CDS = fabric.NewDisjoint(&nodes, &edges)
+	if _, err := graph.AddRealNode(u); err != nil {
+		t.Fatalf("Could not add UI node to graph: %v", err)
+	}
+
+	// temporal covers all CDS edges but no nodes
+	noNodes := make(fabric.NodeList, 0)
+	allEdges := list.ListEdges()
+	sm := make(fabric.SignalingMap)
+	s := make(fabric.SignalsMap)
+	temp := SectionTemporal{
+		Temporal: Temporal{
+			Node: Node{Id: 2, Type: fabric.TemporalNode, Signalers: &sm, Signals: &s},
+		},
+		CDS: fabric.NewDisjoint(&noNodes, &allEdges),
+	}
+	if _, err := graph.AddRealNode(temp); err != nil {
+		t.Fatalf("Could not add Temporal node to graph: %v", err)
+	}
+
+	if n, e := graph.CoveredBy(fabric.UINode); !n || e {
+		t.Fatalf("Incorrect UI coverage: %v, %v", n, e)
+	}
+	if n, e := graph.CoveredBy(fabric.TemporalNode); n || !e {
+		t.Fatalf("Incorrect Temporal coverage: %v, %v", n, e)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}, {1, 3}})
+
+	// add duplicate edges directly
+	graph.Top[nodes[1]] = append(graph.Top[nodes[1]], newTestUI(2), nodes[3], nodes[2])
+
+	if removed := graph.Dedupe(); removed != 3 {
+		t.Fatalf("Incorrect number of duplicate edges removed: %d", removed)
+	}
+	if len(graph.Dependencies(nodes[1])) != 2 {
+		t.Fatalf("Incorrect dependencies after dedupe: %v", graph.Dependencies(nodes[1]))
+	}
+	if graph.Dedupe() != 0 {
+		t.Fatal("Removed edges from a graph without duplicates")
+	}
+}
+
+func TestRevision(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}})
+	rev := graph.Revision()
+	if rev != 4 {
+		t.Fatalf("Incorrect revision: %d", rev)
+	}
+
+	graph.AddRealEdge(2, nodes[3])
+	graph.RemoveRealEdge(1, nodes[2])
+	graph.AddRealEdge(1, nodes[3])
+	graph.RemoveRealEdge(1, nodes[3])
+
+	diff, next := graph.ChangedSince(rev)
+	if next != rev+4 {
+		t.Fatalf("Incorrect revision: %d", next)
+	}
+	if len(diff.AddedNodes) != 0 || len(diff.RemovedNodes) != 0 {
+		t.Fatalf("Incorrect node changes: %v", diff)
+	}
+	if len(diff.AddedEdges) != 1 || diff.AddedEdges[0] != [2]int{2, 3} {
+		t.Fatalf("Incorrect added edges: %v", diff.AddedEdges)
+	}
+	if len(diff.RemovedEdges) != 1 || diff.RemovedEdges[0] != [2]int{1, 2} {
+		t.Fatalf("Incorrect removed edges: %v", diff.RemovedEdges)
+	}
+
+	if diff, _ := graph.ChangedSince(next); !diff.Empty() {
+		t.Fatalf("Changes reported since current revision: %v", diff)
+	}
+}
+
+func TestWouldRemain(t *testing.T) {
+	list := newTestList(3)
+	graph := fabric.NewGraph(*list)
+
+	// UI 1 covers the whole CDS, UI 2 only its first node
+	all := list.ListNodes()
+	allEdges := list.ListEdges()
+	u1 := newTestUI(1)
+	u1.CDS = fabric.NewDisjoint(&all, &allEdges)
+	first := fabric.NodeList{list.Nodes[0]}
+	noEdges := make(fabric.EdgeList, 0)
+	u2 := newTestUI(2)
+	u2.CDS = fabric.NewDisjoint(&first, &noEdges)
+	for _, u := range []UI{u1, u2} {
+		if _, err := graph.AddRealNode(u); err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+	}
+
+	if !graph.WouldRemainCovered(2) || graph.WouldRemainCovered(1) {
+		t.Fatal("Incorrect simulated coverage")
+	}
+	if !graph.WouldRemainUnique(newTestUI(3)) || graph.WouldRemainUnique(u1) {
+		t.Fatal("Incorrect simulated uniqueness")
+	}
+	if len(graph.Top) != 2 {
+		t.Fatal("Simulation modified graph")
+	}
+}
+
+func TestRemoveUI(t *testing.T) {
+	list := newTestList(3)
+
+	graph := fabric.NewGraph()
+	graph.DS = *list
+
+	// UI 1 covers the whole CDS, UI 2 only its first node
+	all := list.ListNodes()
+	allEdges := list.ListEdges()
+	u1 := newTestUI(1)
+	u1.CDS = fabric.NewDisjoint(&all, &allEdges)
+	first := fabric.NodeList{list.Nodes[0]}
+	noEdges := make(fabric.EdgeList, 0)
+	u2 := newTestUI(2)
+	u2.CDS = fabric.NewDisjoint(&first, &noEdges)
+	for _, u := range []UI{u1, u2} {
+		if _, err := graph.AddRealNode(u); err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+	}
+	graph.AddRealEdge(2, u1)
+
+	covered, gaps, err := graph.RemoveUI(1)
+	if err != nil {
+		t.Fatalf("Could not remove UI node: %v", err)
+	}
+	if covered || len(gaps) != 2 {
+		t.Fatalf("Incorrect coverage after removal: %v, %v", covered, gaps)
+	}
+	if _, ok := graph.GetNode(1); ok {
+		t.Fatal("UI node was not removed")
+	}
+	if deps, _ := graph.DependenciesE(2); len(deps) != 0 || len(u2.ListSignals()) != 0 {
+		t.Fatal("Edge to removed UI node was not cleaned up")
+	}
+
+	if _, _, err := graph.RemoveUI(1); err == nil {
+		t.Fatal("Removed a UI node that does not exist")
+	}
+}
+
+func TestStrict(t *testing.T) {
+	graph := fabric.NewGraph()
+	graph.Strict = true
+
+	// UI nodes must have a section
+	if _, err := graph.AddRealNode(newTestUI(1)); err == nil {
+		t.Fatal("Added a UI node without a section in strict mode")
+	}
+
+	list := newTestList(2)
+	nodes := list.ListNodes()
+	edges := list.ListEdges()
+	u := newTestUI(1)
+	u.CDS = fabric.NewDisjoint(&nodes, &edges)
+	u1, err := graph.AddRealNode(u)
+	if err != nil {
+		t.Fatalf("Could not add UI node to graph: %v", err)
+	}
+
+	// ids must be unique
+	dup := newTestUI(1)
+	dup.CDS = u.CDS
+	if _, err := graph.AddRealNode(dup); err == nil {
+		t.Fatal("Added a node with a duplicate id in strict mode")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Added an edge to a node not present in graph in strict mode")
+		}
+	}()
+	graph.AddRealEdge(u1.ID(), newTestUI(2))
+}
+
+func TestAbortImpact(t *testing.T) {
+	// 1 and 2 depend on 3, 4 depends on 1, 5 is unrelated
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{1, 3}, {2, 3}, {4, 1}})
+
+	impact := graph.AbortImpact(3)
+	expected := []int{3, 1, 2, 4}
+	if len(impact) != len(expected) {
+		t.Fatalf("Incorrect abort impact: %v", impact)
+	}
+	for i, n := range impact {
+		if n.ID() != expected[i] {
+			t.Fatalf("Incorrect abort impact: %v", impact)
+		}
+	}
+
+	if len(graph.AbortImpact(6)) != 0 {
+		t.Fatal("Abort impact returned for node not in graph")
+	}
+}
+
+func TestSetCDS(t *testing.T) {
+	graph := fabric.NewGraph()
+	if _, ok := graph.CDS(); ok {
+		t.Fatal("New graph is bound to a CDS")
+	}
+	if _, err := graph.Covered(); err == nil {
+		t.Fatal("Coverage checked without a bound CDS")
+	}
+
+	list := newTestList(2)
+	graph.SetCDS(*list)
+	if covered, err := graph.Covered(); err != nil || covered {
+		t.Fatalf("Incorrect coverage of rebound CDS: %v, %v", covered, err)
+	}
+
+	if _, ok := fabric.NewGraph(*list).CDS(); !ok {
+		t.Fatal("Graph was not bound to CDS at construction")
+	}
+}
+
+// ObserverUI is a UI node that records CDS change notifications
+type ObserverUI struct {
+	UI
+	Changed *[]int
+}
+
+func (u ObserverUI) CDSChanged(ids []int) {
+	*u.Changed = append(*u.Changed, ids...)
+}
+
+func TestNotifyCDSChange(t *testing.T) {
+	list := newTestList(4)
+	graph := fabric.NewGraph(*list)
+
+	sections := fabric.PartitionCDS(*list, 2)
+	u1 := newTestUI(1)
+	u1.CDS = sections[0]
+	var changed []int
+	u2 := ObserverUI{UI: newTestUI(2), Changed: &changed}
+	u2.CDS = sections[1]
+	for _, u := range []fabric.DGNode{u1, u2} {
+		if _, err := graph.AddRealNode(u); err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+	}
+
+	last := list.Nodes[3].ID()
+	if ids := graph.NotifyCDSChange([]int{last}); len(ids) != 1 || ids[0] != 2 {
+		t.Fatalf("Incorrect UI nodes notified: %v", ids)
+	}
+	if len(changed) != 1 || changed[0] != last {
+		t.Fatalf("Incorrect changes observed: %v", changed)
+	}
+
+	if ids := graph.NotifyCDSChange([]int{list.Nodes[0].ID(), last}); len(ids) != 2 {
+		t.Fatalf("Incorrect UI nodes notified: %v", ids)
+	}
+}
+
+func TestUIsForEdgeChange(t *testing.T) {
+	list := newTestList(3)
+	graph := fabric.NewGraph(*list)
+	n0, n1, n2 := list.Nodes[0].ID(), list.Nodes[1].ID(), list.Nodes[2].ID()
+
+	// each UI selects one CDS node along with its edges
+	for i, id := range []int{n0, n2, n1} {
+		u := newTestUI(i + 1)
+		selected := id
+		u.CDS = fabric.SelectSection(*list, func(n fabric.Node) bool { return n.ID() == selected })
+		if _, err := graph.AddRealNode(u); err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+	}
+
+	if ids := graph.UIsForEdgeChange(n0, n1); fmt.Sprint(ids) != "[1 3]" {
+		t.Fatalf("Incorrect UI nodes for edge change: %v", ids)
+	}
+	if ids := graph.UIsForEdgeChange(n1, n0); len(ids) != 0 {
+		t.Fatalf("Incorrect UI nodes for edge change: %v", ids)
+	}
+}
+
+func TestCyclePolicy(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}, {2, 3}})
+
+	graph.CyclePolicy = fabric.Reject
+	if err := graph.AddRealEdgeChecked(3, nodes[1]); err == nil {
+		t.Fatal("Edge creating a cycle was not rejected")
+	}
+	if graph.CycleDetect() {
+		t.Fatal("Rejected edge was inserted")
+	}
+	if err := graph.AddRealEdgeChecked(1, nodes[3]); err != nil {
+		t.Fatalf("Could not add edge: %v", err)
+	}
+
+	// the lowest weight edges of the cycles 3 -> 1 -> 3 and 3 -> 1 -> 2 -> 3 are dropped
+	graph.CyclePolicy = fabric.Break
+	graph.SetEdgeWeight(1, 2, 0.5)
+	graph.SetEdgeWeight(1, 3, 0.5)
+	if err := graph.AddRealEdgeChecked(3, nodes[1]); err != nil {
+		t.Fatalf("Could not add edge: %v", err)
+	}
+	if graph.CycleDetect() {
+		t.Fatal("Cycle was not broken")
+	}
+	if deps, _ := graph.DependenciesE(3); len(deps) != 1 {
+		t.Fatal("New edge was dropped instead of the weakest edge")
+	}
+	if deps, _ := graph.DependenciesE(1); len(deps) != 0 {
+		t.Fatalf("Incorrect edges dropped: %v", deps)
+	}
+
+	graph.CyclePolicy = fabric.Allow
+	graph.AddRealEdge(2, nodes[2])
+	if !graph.CycleDetect() {
+		t.Fatal("Allowed cycle was not inserted")
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{1, 2}, {3, 4}})
+	graph.MaxDepth = 2
+
+	// 1 -> 2 -> 3 -> 4 would be three edges long
+	if err := graph.AddRealEdgeChecked(2, nodes[3]); err == nil {
+		t.Fatal("Edge exceeding the maximum depth was added")
+	}
+	graph.AddRealEdge(2, nodes[3])
+	if deps, _ := graph.DependenciesE(2); len(deps) != 0 {
+		t.Fatal("Edge exceeding the maximum depth was added")
+	}
+
+	if err := graph.AddRealEdgeChecked(2, nodes[5]); err != nil {
+		t.Fatalf("Could not add edge within the maximum depth: %v", err)
+	}
+
+	// 1 -> 3 -> 2 -> 4 is three edges long, even though 2 and 3 form a cycle
+	graph, nodes = chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{1, 2}, {1, 3}, {2, 3}, {3, 2}, {2, 4}})
+	graph.MaxDepth = 3
+	if err := graph.AddRealEdgeChecked(5, nodes[1]); err == nil {
+		t.Fatal("Edge exceeding the maximum depth through a cycle was added")
+	}
+}
+
+func TestSharedSectionNodes(t *testing.T) {
+	list := newTestList(3)
+	graph := fabric.NewGraph(*list)
+
+	// UI 1 owns the whole list, UI 2 only the first node
+	u1 := newTestUI(1)
+	u1.CDS = fabric.NewBranch(list.Nodes[0], *list)
+	u2 := newTestUI(2)
+	u2.CDS = fabric.NewFrontierSection(list.Nodes[0], 0, *list)
+	for _, u := range []UI{u1, u2} {
+		if _, err := graph.AddRealNode(u); err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+	}
+
+	shared := graph.SharedSectionNodes()
+	uis := shared[list.Nodes[0].ID()]
+	if len(shared) != 1 || len(uis) != 2 || uis[0] != 1 || uis[1] != 2 {
+		t.Fatalf("Incorrect shared section nodes: %v", shared)
+	}
+}
+
+func TestPurgeVirtuals(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2}, [][2]int{{1, 2}})
+
+	var vuis []fabric.DGNode
+	for _, id := range []int{3, 4, 5} {
+		vu := newTestUI(id)
+		vu.Virtual = true
+		n, err := graph.AddVUI(vu)
+		if err != nil {
+			t.Fatalf("Could not add VUI node to graph: %v", err)
+		}
+		vuis = append(vuis, n)
+	}
+
+	// 4 depends on virtual node 3, real node 2 depends on virtual node 5
+	graph.AddRealEdge(4, vuis[0])
+	graph.AddRealEdge(2, vuis[2])
+
+	removed, err := graph.PurgeVirtuals()
+	if err == nil {
+		t.Fatal("Removed a virtual node with non-virtual dependents")
+	}
+	if len(removed) != 2 || removed[0] != 3 || removed[1] != 4 {
+		t.Fatalf("Incorrect virtual nodes removed: %v", removed)
+	}
+	if len(graph.Top) != 3 || len(nodes[1].ListSignals()) != 1 {
+		t.Fatal("Real graph was modified")
+	}
+
+	// VDGs are removed along with all of their nodes
+	vdg, err := fabric.NewVDG(graph)
+	if err != nil {
+		t.Fatalf("Could not create VDG and add to graph: %v", err)
+	}
+	v6, v7 := newLiveVirtual(6, fabric.Running), newLiveVirtual(7, fabric.Running)
+	for _, v := range []LiveVirtual{v6, v7} {
+		if _, err := vdg.AddVirtualNode(v); err != nil {
+			t.Fatalf("Could not add Virtual node to VDG: %v", err)
+		}
+	}
+	vdg.AddVirtualEdge(7, v6)
+
+	removed, _ = graph.PurgeVirtuals()
+	if len(removed) != 2 || removed[0] != 6 || removed[1] != 7 {
+		t.Fatalf("Incorrect VDG nodes removed: %v", removed)
+	}
+	if len(graph.VDG) != 0 || len(vdg.Top) != 0 || len(v7.ListSignals()) != 0 {
+		t.Fatal("VDG was not removed")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2}, [][2]int{{1, 2}})
+
+	if _, err := graph.AddRealNode(nodes[1]); !errors.Is(err, fabric.ErrNodeExists) {
+		t.Fatalf("Incorrect error for existing node: %v", err)
+	}
+	if _, err := graph.DependenciesE(3); !errors.Is(err, fabric.ErrNodeNotFound) {
+		t.Fatalf("Incorrect error for missing node: %v", err)
+	}
+	if err := graph.RemoveVUI(nodes[1]); !errors.Is(err, fabric.ErrNotVirtual) {
+		t.Fatalf("Incorrect error for removing real node: %v", err)
+	}
+
+	graph.CyclePolicy = fabric.Reject
+	if err := graph.AddRealEdgeChecked(2, nodes[1]); !errors.Is(err, fabric.ErrCycle) {
+		t.Fatalf("Incorrect error for cyclic edge: %v", err)
+	}
+}
+
+// NamedUI is a UI node whose section belongs to a named CDS
+type NamedUI struct {
+	UI
+	Name string
+}
+
+func (u NamedUI) CDSName() string {
+	return u.Name
+}
+
+func TestCoveredMulti(t *testing.T) {
+	a := newTestList(3)
+	b := newTestList(2)
+	graph := fabric.NewGraph()
+
+	// "a" is covered by its UI; "b" only by a UI of "a"
+	ua := NamedUI{UI: newTestUI(1), Name: "a"}
+	ua.CDS = fabric.NewBranch(a.Nodes[0], *a)
+	ub := NamedUI{UI: newTestUI(2), Name: "a"}
+	ub.CDS = fabric.NewBranch(b.Nodes[0], *b)
+	for _, u := range []NamedUI{ua, ub} {
+		if _, err := graph.AddRealNode(u); err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+	}
+
+	covered := graph.CoveredMulti(map[string]fabric.CDS{"a": *a, "b": *b})
+	if !covered["a"] || covered["b"] {
+		t.Fatalf("Incorrect coverage: %v", covered)
+	}
+}
+
+func TestNewNode(t *testing.T) {
+	graph := fabric.NewGraph()
+
+	n, err := graph.NewNode(func(id int) fabric.DGNode {
+		return newTestUI(id)
+	})
+	if err != nil {
+		t.Fatalf("Could not create node: %v", err)
+	}
+	if _, ok := graph.GetNode(n.ID()); !ok {
+		t.Fatal("Node was not added to graph")
+	}
+
+	if _, err := graph.NewNode(func(int) fabric.DGNode { return newTestUI(n.ID()) }); err == nil {
+		t.Fatal("Created node without allocated ID")
+	}
+}
+
+func TestCycleDetectContext(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}, {2, 3}})
+
+	cyclic, err := graph.CycleDetectContext(context.Background())
+	if err != nil || cyclic {
+		t.Fatalf("Incorrect cycle detection: %v, %v", cyclic, err)
+	}
+
+	graph.AddRealEdge(3, nodes[1])
+	cyclic, err = graph.CycleDetectContext(context.Background())
+	if err != nil || !cyclic {
+		t.Fatalf("Incorrect cycle detection: %v, %v", cyclic, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := graph.CycleDetectContext(ctx); err != context.Canceled {
+		t.Fatalf("Cycle detection did not honor cancellation: %v", err)
+	}
+}
+
+func TestWouldCycle(t *testing.T) {
+	// 1 depends on 2, 2 depends on 3
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{1, 2}, {2, 3}})
+
+	if !graph.Reachable(1, 3) || graph.Reachable(3, 1) {
+		t.Fatal("Incorrect reachability")
+	}
+	if !graph.WouldCycle(3, 1) || !graph.WouldCycle(2, 2) {
+		t.Fatal("Cycle was not predicted")
+	}
+	if graph.WouldCycle(1, 3) || graph.WouldCycle(3, 4) {
+		t.Fatal("Cycle was predicted for an acyclic edge")
+	}
+}
+
+func TestBuildTemporalDAG(t *testing.T) {
+	// a ring of 3 CDS nodes
+	list := newTestList(3)
+	last := list.Nodes[2].(ElementNode)
+	list.NewElementEdge(&last, list.Root)
+
+	graph := fabric.NewGraph(*list)
+	all := list.ListNodes()
+	allEdges := list.ListEdges()
+	u := newTestUI(1)
+	u.CDS = fabric.NewDisjoint(&all, &allEdges)
+	if _, err := graph.AddRealNode(u); err != nil {
+		t.Fatalf("Could not add UI node to graph: %v", err)
+	}
+
+	dag, err := graph.BuildTemporalDAG(1, func(id int) fabric.Temporal {
+		sm := make(fabric.SignalingMap)
+		s := make(fabric.SignalsMap)
+		return Temporal{
+			Node:   Node{Id: id, Type: fabric.TemporalNode, Signalers: &sm, Signals: &s},
+			UIRoot: u,
+		}
+	})
+	if err != nil {
+		t.Fatalf("Could not build temporal DAG: %v", err)
+	}
+
+	if len(dag.Top) != 4 || dag.CycleDetect() {
+		t.Fatal("Incorrect temporal DAG")
+	}
+	order, _ := dag.TopoSort()
+	if order[0].ID() != 1 || !dag.IsTree() {
+		t.Fatal("Temporal DAG is not a chain rooted at the UI node")
+	}
+
+	// the DAG is rooted at a proxy of the UI, leaving the graph's UI node untouched
+	if _, ok := order[0].(*fabric.UIProxy); !ok || len(order[0].ListSignalers()) != 1 {
+		t.Fatal("Temporal DAG is not rooted at a UI proxy")
+	}
+	if len(u.ListSignalers()) != 0 || len(graph.AsymmetricChannels()) != 0 {
+		t.Fatal("Building the temporal DAG modified the graph")
+	}
+
+	if _, err := graph.BuildTemporalDAG(2, nil); err == nil {
+		t.Fatal("Built temporal DAG for node not in graph")
+	}
+}
+
+func TestNewSeededGraph(t *testing.T) {
+	ids := func() []int {
+		graph := fabric.NewSeededGraph(42)
+		var ids []int
+		for i := 0; i < 3; i++ {
+			n, err := graph.NewNode(func(id int) fabric.DGNode { return newTestUI(id) })
+			if err != nil {
+				t.Fatalf("Could not create node: %v", err)
+			}
+			ids = append(ids, n.ID())
+		}
+		return ids
+	}
+
+	first, second := ids(), ids()
+	if fmt.Sprint(first) != fmt.Sprint(second) {
+		t.Fatalf("Seeded ids are not reproducible: %v, %v", first, second)
+	}
+	if first[0] == first[1] || first[1] == first[2] {
+		t.Fatalf("Seeded ids are not unique: %v", first)
+	}
+
+	// concurrent draws from the seeded generator are safe
+	graph := fabric.NewSeededGraph(42)
+	done := make(chan int)
+	for i := 0; i < 4; i++ {
+		go func() {
+			done <- graph.GenID()
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+}
@@ -0,0 +1,60 @@
+// +build test
+
+package fabric_test
+
+import (
+	"testing"
+
+	"github.com/JKhawaja/fabric"
+)
+
+// PriorityPoset orders nodes by priority: every node depends on all
+// nodes with a lower priority
+type PriorityPoset struct {
+	graph *fabric.Graph
+}
+
+func (p *PriorityPoset) Graph() *fabric.Graph {
+	return p.graph
+}
+
+func (p *PriorityPoset) InitGraph(nodes []fabric.DGNode) *fabric.Graph {
+	p.graph = fabric.NewGraph()
+	for _, n := range nodes {
+		p.graph.AddRealNode(n)
+	}
+	for _, n := range nodes {
+		p.Order(n)
+	}
+	return p.graph
+}
+
+func (p *PriorityPoset) Order(n fabric.DGNode) error {
+	for m := range p.graph.Top {
+		if m.GetPriority() < n.GetPriority() {
+			p.graph.AddRealEdge(n.ID(), m)
+		}
+	}
+	return nil
+}
+
+func TestBuildFromPoset(t *testing.T) {
+	nodes := []fabric.DGNode{
+		PriorityUI{UI: newTestUI(1), Priority: 2},
+		PriorityUI{UI: newTestUI(2), Priority: 1},
+		PriorityUI{UI: newTestUI(3), Priority: 3},
+	}
+
+	graph, err := fabric.BuildFromPoset(&PriorityPoset{}, nodes)
+	if err != nil {
+		t.Fatalf("Could not build graph from poset: %v", err)
+	}
+
+	order, _ := graph.TopoSort()
+	if len(order) != 3 || order[0].ID() != 2 || order[2].ID() != 3 {
+		t.Fatalf("Incorrect poset order: %v", order)
+	}
+	if len(nodes[2].ListSignals()) != 2 {
+		t.Fatal("Signaling was not wired")
+	}
+}
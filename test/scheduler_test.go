@@ -0,0 +1,279 @@
+// +build test
+
+package fabric_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/JKhawaja/fabric"
+)
+
+func TestScheduler(t *testing.T) {
+	// 4 depends on 1, 2 and 3
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{4, 3}, {4, 2}, {4, 1}})
+	sched := fabric.NewScheduler(graph)
+
+	var order []int
+	for {
+		n, ok := sched.Next()
+		if !ok {
+			break
+		}
+		order = append(order, n.ID())
+		sched.Complete(n.ID())
+	}
+
+	if fmt.Sprint(order) != "[1 2 3 4]" {
+		t.Fatalf("Incorrect FIFO schedule: %v", order)
+	}
+}
+
+func TestSchedulerWeightedRoundRobin(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1, 2}, nil)
+	sched := fabric.NewScheduler(graph)
+	sched.SetTieBreak(fabric.WeightedRoundRobin)
+	sched.SetWeight(1, 3)
+
+	// node 1 should get three turns for every turn of node 2
+	turns := make(map[int]int)
+	for i := 0; i < 8; i++ {
+		n, ok := sched.Next()
+		if !ok {
+			t.Fatal("No ready node")
+		}
+		turns[n.ID()]++
+		sched.Requeue(n)
+	}
+
+	if turns[1] != 6 || turns[2] != 2 {
+		t.Fatalf("Incorrect weighted round-robin turns: %v", turns)
+	}
+}
+
+func TestReadyNodes(t *testing.T) {
+	// 3 depends on 1 and 2
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{3, 1}, {3, 2}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ready := graph.ReadyNodes(ctx)
+
+	var order []int
+	for n := range ready {
+		order = append(order, n.ID())
+		// workers signal completion to dependents once done
+		if n.ID() != 3 {
+			go func(n fabric.DGNode) {
+				time.Sleep(5 * time.Millisecond)
+				n.Signal(fabric.NodeSignal{Value: fabric.Completed})
+			}(nodes[n.ID()])
+		}
+	}
+
+	if ctx.Err() != nil {
+		t.Fatal("Ready nodes were not all emitted")
+	}
+	if fmt.Sprint(order) != "[1 2 3]" {
+		t.Fatalf("Incorrect ready order: %v", order)
+	}
+}
+
+func TestRunRecoverPanics(t *testing.T) {
+	// 2 depends on 1, 3 is independent
+	graph, _ := chainGraph(t, []int{1, 2, 3}, [][2]int{{2, 1}})
+	graph.RecoverPanics = true
+
+	ran := make(chan int, 3)
+	signals, err := graph.Run(context.Background(), func(ctx context.Context, n fabric.DGNode) error {
+		ran <- n.ID()
+		if n.ID() == 1 {
+			panic("procedure bug")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Could not run graph: %v", err)
+	}
+
+	if s := signals[1]; s.Value != fabric.Aborted || s.Payload != "procedure bug" {
+		t.Fatalf("Panic was not converted to an Aborted signal: %v", s)
+	}
+	if _, ok := signals[2]; ok {
+		t.Fatal("Dependent of aborted node was run")
+	}
+	if signals[3].Value != fabric.Completed || len(ran) != 2 {
+		t.Fatal("Independent node was not run")
+	}
+}
+
+func TestDependencyModeAny(t *testing.T) {
+	// 3 depends on 1 and 2
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{3, 1}, {3, 2}})
+	graph.SetDependencyMode(3, fabric.Any)
+
+	sched := fabric.NewScheduler(graph)
+	sched.Next()
+	sched.Next()
+	sched.Complete(1)
+	if n, ok := sched.Next(); !ok || n.ID() != 3 {
+		t.Fatal("Node with Any dependency mode was not ready after one dependency completed")
+	}
+
+	done := make(chan map[int]fabric.NodeSignal)
+	go func() {
+		signals, _, err := graph.Await(context.Background(), 3)
+		if err != nil {
+			t.Errorf("Could not await dependencies: %v", err)
+		}
+		done <- signals
+	}()
+	nodes[2].ListSignalers()[3] <- fabric.NodeSignal{Value: fabric.Completed}
+
+	select {
+	case signals := <-done:
+		if len(signals) != 1 || signals[2].Value != fabric.Completed {
+			t.Fatalf("Incorrect awaited signals: %v", signals)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Await waited for all dependencies")
+	}
+
+	graph.SetDependencyMode(3, fabric.All)
+	if graph.DependencyMode(3) != fabric.All {
+		t.Fatal("Dependency mode was not reset")
+	}
+}
+
+func TestDependencyModeAnyFailed(t *testing.T) {
+	// 3 depends on 1 and 2, both of which abort
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{3, 1}, {3, 2}})
+	graph.SetDependencyMode(3, fabric.Any)
+
+	done := make(chan error)
+	go func() {
+		_, _, err := graph.Await(context.Background(), 3)
+		done <- err
+	}()
+	nodes[1].ListSignalers()[3] <- fabric.NodeSignal{Value: fabric.Aborted}
+	close(nodes[2].ListSignalers()[3])
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, fabric.ErrDependenciesFailed) {
+			t.Fatalf("Await did not report failed dependencies: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Await blocked after all dependencies failed")
+	}
+
+	graph, nodes = chainGraph(t, []int{1, 2, 3}, [][2]int{{3, 1}, {3, 2}})
+	graph.SetDependencyMode(3, fabric.Any)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for n := range graph.ReadyNodes(ctx) {
+		if n.ID() == 3 {
+			t.Fatal("Node was emitted after all dependencies failed")
+		}
+		go nodes[n.ID()].Signal(fabric.NodeSignal{Value: fabric.Aborted})
+	}
+	if ctx.Err() != nil {
+		t.Fatal("ReadyNodes channel was not closed after all dependencies failed")
+	}
+	if graph.Health().States[fabric.Aborted] != 1 {
+		t.Fatal("Node was not reported as aborted")
+	}
+
+	graph, _ = chainGraph(t, []int{1, 2, 3}, [][2]int{{3, 1}, {3, 2}})
+	graph.SetDependencyMode(3, fabric.Any)
+	signals, err := graph.Run(context.Background(), func(ctx context.Context, n fabric.DGNode) error {
+		return fmt.Errorf("node %d failed", n.ID())
+	})
+	if err != nil {
+		t.Fatalf("Could not run graph: %v", err)
+	}
+	if s := signals[3]; s.Value != fabric.Aborted || !errors.Is(s.Reason(), fabric.ErrDependenciesFailed) {
+		t.Fatalf("Node was not aborted after all dependencies failed: %v", s)
+	}
+}
+
+func TestRunNodeTimeout(t *testing.T) {
+	// 2 depends on 1, which ignores its context and times out
+	graph, _ := chainGraph(t, []int{1, 2, 3}, [][2]int{{2, 1}})
+	graph.SetNodeTimeout(1, 10*time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+	signals, err := graph.Run(context.Background(), func(ctx context.Context, n fabric.DGNode) error {
+		if n.ID() == 1 {
+			<-release
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Could not run graph: %v", err)
+	}
+
+	if s := signals[1]; s.Value != fabric.Aborted || !errors.Is(s.Reason(), context.DeadlineExceeded) {
+		t.Fatalf("Timed out node was not aborted: %v", s)
+	}
+	if _, ok := signals[2]; ok {
+		t.Fatal("Dependent of timed out node was run")
+	}
+	if signals[3].Value != fabric.Completed {
+		t.Fatal("Independent node was not run")
+	}
+}
+
+// RecordedProcedure is a Procedure that records its commits
+type RecordedProcedure struct {
+	Procedure
+	Log *[]int
+	Err error
+}
+
+func (p RecordedProcedure) Commit(n fabric.DGNode) error {
+	*p.Log = append(*p.Log, p.Id)
+	return p.Err
+}
+
+// OrderedUI is a UI node whose access procedures are ordered by a procedure graph
+type OrderedUI struct {
+	UI
+	Order *fabric.ProcedureGraph
+}
+
+func (u OrderedUI) ProcedureOrder() ([]fabric.AccessType, error) {
+	return u.Order.TopoSort()
+}
+
+func TestRunProcedureOrder(t *testing.T) {
+	var log []int
+	u := newTestUI(1)
+	*u.AccessProcedures = fabric.ProcedureList{
+		RecordedProcedure{Procedure: Procedure{Id: 1}, Log: &log},
+		RecordedProcedure{Procedure: Procedure{Id: 2}, Log: &log},
+		RecordedProcedure{Procedure: Procedure{Id: 3}, Log: &log, Err: fmt.Errorf("write failed")},
+	}
+	order := fabric.NewProcedureGraph(*u.AccessProcedures)
+	order.AddOrder(2, 1)
+
+	graph := fabric.NewGraph()
+	if _, err := graph.AddRealNode(OrderedUI{UI: u, Order: order}); err != nil {
+		t.Fatalf("Could not add UI node to graph: %v", err)
+	}
+
+	signals, err := graph.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Could not run graph: %v", err)
+	}
+	if len(log) != 3 || log[0] != 2 || log[1] != 1 || log[2] != 3 {
+		t.Fatalf("Procedures were not committed in order: %v", log)
+	}
+	if s := signals[1]; s.Value != fabric.Aborted || s.Reason() == nil {
+		t.Fatalf("Failed procedure did not abort node: %v", s)
+	}
+}
@@ -0,0 +1,227 @@
+// +build test
+
+package fabric_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/JKhawaja/fabric"
+)
+
+// newTestList creates a linear List CDS with the given number of nodes
+func newTestList(size int) *List {
+	list := NewList()
+	prev := list.Root
+	for i := 1; i < size; i++ {
+		n := list.NewElementNode()
+		list.NewElementEdge(prev, n)
+		prev = n
+	}
+	return list
+}
+
+func TestSelectSection(t *testing.T) {
+	list := newTestList(4)
+	first := list.Nodes[1].ID()
+	last := list.Nodes[3].ID()
+
+	s := fabric.SelectSection(*list, func(n fabric.Node) bool {
+		return n.ID() == first || n.ID() == last
+	})
+
+	nodes := *s.ListNodes()
+	if len(nodes) != 2 {
+		t.Fatalf("Incorrect number of selected nodes: %d", len(nodes))
+	}
+
+	// the second node has an incoming and an outgoing edge, the last node
+	// only an incoming edge
+	edges := *s.ListEdges()
+	if len(edges) != 3 {
+		t.Fatalf("Incorrect number of selected edges: %d", len(edges))
+	}
+}
+
+func TestSectionToCDS(t *testing.T) {
+	list := newTestList(5)
+
+	// create a partition of the list, then a branch of that partition
+	partition := fabric.NewPartition(list.Nodes[1], list.Nodes[3], *list)
+	c := fabric.SectionToCDS(partition)
+	if len(c.ListNodes()) != 3 || len(c.ListEdges()) != 2 {
+		t.Fatalf("Incorrect CDS from section: %d nodes, %d edges", len(c.ListNodes()), len(c.ListEdges()))
+	}
+
+	branch := fabric.NewBranch(c.ListNodes()[1], c)
+	if len(*branch.ListNodes()) != 2 || len(*branch.ListEdges()) != 1 {
+		t.Fatalf("Incorrect branch of section CDS: %d nodes, %d edges", len(*branch.ListNodes()), len(*branch.ListEdges()))
+	}
+}
+
+func TestNodeEqual(t *testing.T) {
+	list := newTestList(3)
+
+	// the list root is stored as a pointer while edges reference node values;
+	// the default id equality still matches them
+	branch := fabric.NewBranch(list.Nodes[0], *list)
+	if len(*branch.ListNodes()) != 3 || len(*branch.ListEdges()) != 2 {
+		t.Fatalf("Incorrect branch: %d nodes, %d edges", len(*branch.ListNodes()), len(*branch.ListEdges()))
+	}
+
+	// custom equality treating every node as distinct
+	defer func(eq func(a, b fabric.Node) bool) {
+		fabric.NodeEqual = eq
+	}(fabric.NodeEqual)
+	fabric.NodeEqual = func(a, b fabric.Node) bool {
+		return false
+	}
+
+	nodes := fabric.NodeList{list.Nodes[1]}
+	subset := fabric.NewSubset(&nodes, *list)
+	if len(*subset.ListEdges()) != 0 {
+		t.Fatal("Custom node equality was not used")
+	}
+}
+
+func TestFrontierSection(t *testing.T) {
+	list := newTestList(6)
+	start := list.Nodes[2]
+
+	s := fabric.NewFrontierSection(start, 1, *list)
+	nodes := *s.ListNodes()
+	if len(nodes) != 3 || !fabric.ContainsNode(nodes, list.Nodes[1]) || !fabric.ContainsNode(nodes, list.Nodes[3]) {
+		t.Fatalf("Incorrect frontier nodes: %v", nodes)
+	}
+	if len(*s.ListEdges()) != 2 {
+		t.Fatalf("Incorrect number of frontier edges: %d", len(*s.ListEdges()))
+	}
+
+	s = fabric.NewFrontierSection(start, 0, *list)
+	if len(*s.ListNodes()) != 1 || len(*s.ListEdges()) != 0 {
+		t.Fatal("Incorrect frontier of depth 0")
+	}
+
+	s = fabric.NewFrontierSection(start, 10, *list)
+	if len(*s.ListNodes()) != 6 || len(*s.ListEdges()) != 5 {
+		t.Fatal("Frontier does not cover the whole list")
+	}
+}
+
+func TestPartitionCDS(t *testing.T) {
+	list := newTestList(7)
+
+	sections := fabric.PartitionCDS(*list, 3)
+	if len(sections) != 3 {
+		t.Fatalf("Incorrect number of sections: %d", len(sections))
+	}
+
+	sizes := []int{3, 2, 2}
+	seen := make(map[int]bool)
+	edges := 0
+	for i, s := range sections {
+		if len(*s.ListNodes()) != sizes[i] {
+			t.Fatalf("Section %d has %d nodes instead of %d", i, len(*s.ListNodes()), sizes[i])
+		}
+		for _, n := range *s.ListNodes() {
+			if seen[n.ID()] {
+				t.Fatalf("Node %d is in more than one section", n.ID())
+			}
+			seen[n.ID()] = true
+		}
+		edges += len(*s.ListEdges())
+	}
+	if len(seen) != 7 || edges != 6 {
+		t.Fatalf("Sections do not cover the CDS: %d nodes, %d edges", len(seen), edges)
+	}
+
+	// sections of a linear CDS grown from its start are contiguous
+	first := *sections[0].ListNodes()
+	if !fabric.ContainsNode(first, list.Nodes[1]) || !fabric.ContainsNode(first, list.Nodes[2]) {
+		t.Fatal("First section is not connected")
+	}
+
+	if len(fabric.PartitionCDS(*list, 10)) != 7 {
+		t.Fatal("Incorrect number of sections for more sections than nodes")
+	}
+
+	// duplicate node entries are assigned once
+	list.Nodes = append(list.Nodes, list.Nodes[1], list.Nodes[2])
+	seen = make(map[int]bool)
+	for _, s := range fabric.PartitionCDS(*list, 3) {
+		for _, n := range *s.ListNodes() {
+			if seen[n.ID()] {
+				t.Fatalf("Duplicate node %d was assigned more than once", n.ID())
+			}
+			seen[n.ID()] = true
+		}
+	}
+	if len(seen) != 7 {
+		t.Fatalf("Sections do not cover the CDS with duplicate nodes: %d nodes", len(seen))
+	}
+}
+
+func TestDisjointed(t *testing.T) {
+	list := newTestList(4)
+	sections := fabric.PartitionCDS(*list, 2)
+	if !fabric.Disjointed(sections[0], sections[1]) {
+		t.Fatal("Partitioned sections are not disjoint")
+	}
+
+	// a branch from the second node overlaps with both partitions
+	branch := fabric.NewBranch(list.Nodes[1], *list)
+	if fabric.Disjointed(sections[0], branch) || fabric.Disjointed(branch, sections[1]) {
+		t.Fatal("Overlapping sections are disjoint")
+	}
+
+	// sharing an edge is enough to overlap
+	noNodes := make(fabric.NodeList, 0)
+	edges := fabric.EdgeList{list.Edges[0]}
+	if fabric.Disjointed(fabric.NewDisjoint(&noNodes, &edges), sections[0]) {
+		t.Fatal("Sections sharing an edge are disjoint")
+	}
+}
+
+// ContainerNode is a List element node holding another List
+type ContainerNode struct {
+	ElementNode
+	Sub *List
+}
+
+func (c ContainerNode) SubCDS() fabric.CDS {
+	return *c.Sub
+}
+
+func TestFlattenCDS(t *testing.T) {
+	inner := newTestList(2)
+	middle := newTestList(2)
+	middle.Nodes[1] = ContainerNode{ElementNode: ElementNode{Id: middle.Nodes[1].ID()}, Sub: inner}
+	outer := newTestList(2)
+	outer.Nodes[0] = ContainerNode{ElementNode: ElementNode{Id: outer.Nodes[0].ID()}, Sub: middle}
+
+	flat := fabric.FlattenCDS(*outer)
+	nodes := flat.ListNodes()
+	if len(nodes) != 6 || len(flat.ListEdges()) != 3 {
+		t.Fatalf("Incorrect flattened CDS size: %d nodes, %d edges", len(nodes), len(flat.ListEdges()))
+	}
+
+	ids := make(map[int]bool)
+	for _, n := range nodes {
+		ids[n.ID()] = true
+	}
+	if len(ids) != 6 || !ids[outer.Nodes[0].ID()] || !ids[outer.Nodes[1].ID()] {
+		t.Fatal("Flattened CDS node ids are not unique")
+	}
+
+	innerNode := nodes[5].(fabric.FlatNode)
+	want := fmt.Sprintf("%d/%d", outer.Nodes[0].ID(), middle.Nodes[1].ID())
+	if innerNode.Namespace != want || innerNode.Node.ID() != inner.Nodes[1].ID() {
+		t.Fatalf("Incorrect flattened node: %v", innerNode)
+	}
+
+	// sections can cover the nested structure
+	s := fabric.SelectSection(flat, func(n fabric.Node) bool { return true })
+	if len(*s.ListNodes()) != 6 || len(*s.ListEdges()) != 3 {
+		t.Fatal("Section did not cover flattened CDS")
+	}
+}
@@ -0,0 +1,688 @@
+// +build test
+
+package fabric_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/JKhawaja/fabric"
+)
+
+// Checkpoint is a domain-specific signal value
+type Checkpoint struct {
+	Final bool
+}
+
+func (c Checkpoint) IsTerminal() bool {
+	return c.Final
+}
+
+func TestSignalValue(t *testing.T) {
+	if !fabric.Aborted.IsTerminal() || fabric.Started.IsTerminal() || fabric.AbortRetry.IsTerminal() {
+		t.Fatal("Incorrectly classified built-in terminal signals")
+	}
+
+	s := fabric.NodeSignal{Value: fabric.Started}
+	if s.IsTerminal() {
+		t.Fatal("Started signal classified as terminal")
+	}
+
+	s.Custom = Checkpoint{Final: true}
+	if !s.IsTerminal() {
+		t.Fatal("Custom signal value was not used")
+	}
+}
+
+func TestCompletionGroup(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 3}, {2, 3}})
+	tracker := graph.CompletionGroup()
+
+	tracker.Done(3)
+	tracker.Done(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := tracker.Wait(ctx); err == nil {
+		t.Fatal("Wait returned before all root boundary nodes completed")
+	}
+
+	tracker.Done(2)
+	if err := tracker.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait did not return after completion: %v", err)
+	}
+}
+
+func TestNodeTimeout(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2}, [][2]int{{2, 1}})
+	graph.SetNodeTimeout(1, 5*time.Millisecond)
+
+	stop, err := graph.StartWatchdog(1)
+	if err != nil {
+		t.Fatalf("Could not start watchdog: %v", err)
+	}
+
+	// node 2 (the dependent) receives the abort
+	select {
+	case sig := <-nodes[2].ListSignals()[1]:
+		if sig.Value != fabric.Aborted {
+			t.Fatalf("Incorrect signal from timed out node: %v", sig.Value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out node did not abort")
+	}
+
+	if h := graph.Health(); h.States[fabric.Aborted] != 1 {
+		t.Fatalf("Abort of timed out node was not reported: %v", h.States)
+	}
+
+	if stop() {
+		t.Fatal("Watchdog was stopped after it fired")
+	}
+}
+
+func TestAggregatorNode(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2, 4}, nil)
+
+	agg, err := graph.AddRealNode(fabric.NewAggregatorNode(3, fabric.UINode))
+	if err != nil {
+		t.Fatalf("Could not add aggregator node to graph: %v", err)
+	}
+	graph.AddRealEdge(3, nodes[1])
+	graph.AddRealEdge(3, nodes[2])
+	graph.AddRealEdge(4, agg)
+
+	go nodes[1].Signal(fabric.NodeSignal{Value: fabric.Started})
+	go func() {
+		nodes[2].Signal(fabric.NodeSignal{Value: fabric.Aborted})
+		nodes[1].Signal(fabric.NodeSignal{Value: fabric.Completed})
+	}()
+
+	done := make(chan fabric.Signal)
+	go func() {
+		done <- agg.(*fabric.AggregatorNode).Join()
+	}()
+
+	sig := <-nodes[4].ListSignals()[3]
+	if sig.Value != fabric.Aborted || <-done != fabric.Aborted {
+		t.Fatalf("Incorrect aggregated signal: %v", sig.Value)
+	}
+
+	// terminal custom signal values end a dependency's execution as well
+	go nodes[1].Signal(fabric.NodeSignal{Value: fabric.Started, Custom: Checkpoint{Final: true}})
+	go nodes[2].Signal(fabric.NodeSignal{Value: fabric.Completed})
+	go func() {
+		done <- agg.(*fabric.AggregatorNode).Join()
+	}()
+
+	sig = <-nodes[4].ListSignals()[3]
+	if sig.Value != fabric.Completed || <-done != fabric.Completed {
+		t.Fatalf("Incorrect aggregated signal for custom value: %v", sig.Value)
+	}
+}
+
+func TestSignalsAndSignalers(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2}, [][2]int{{2, 1}})
+	graph.SignalsAndSignalers()
+
+	go nodes[1].Signal(fabric.NodeSignal{Value: fabric.Completed})
+	if sig := <-nodes[2].ListSignals()[1]; sig.Value != fabric.Completed {
+		t.Fatalf("Incorrect signal received: %v", sig.Value)
+	}
+
+	// existing (e.g. buffered) channels are preserved unless forced
+	buffered := make(chan fabric.NodeSignal, 2)
+	nodes[1].UpdateSignaling(fabric.SignalingMap{2: buffered}, nodes[1].ListSignals())
+	graph.SignalsAndSignalers()
+	if nodes[1].ListSignalers()[2] != buffered {
+		t.Fatal("Existing signaling channel was replaced")
+	}
+	nodes[1].Signal(fabric.NodeSignal{Value: fabric.Started})
+	if sig := <-nodes[2].ListSignals()[1]; sig.Value != fabric.Started {
+		t.Fatalf("Incorrect signal received: %v", sig.Value)
+	}
+
+	graph.RebuildSignaling(true)
+	if nodes[1].ListSignalers()[2] == buffered {
+		t.Fatal("Signaling channel was not rebuilt")
+	}
+
+	// signaling through a transport
+	graph.Transport = fabric.NewLocalTransport(1)
+	graph.SignalsAndSignalers()
+
+	go nodes[1].Signal(fabric.NodeSignal{Value: fabric.Aborted})
+	if sig := <-nodes[2].ListSignals()[1]; sig.Value != fabric.Aborted {
+		t.Fatalf("Incorrect signal received through transport: %v", sig.Value)
+	}
+}
+
+// FailingTransport is a Transport that can not send any signals
+type FailingTransport struct{}
+
+func (FailingTransport) Send(srcID, dstID int, sig fabric.NodeSignal) error {
+	return fmt.Errorf("transport is down")
+}
+
+func (FailingTransport) Recv(srcID, dstID int) <-chan fabric.NodeSignal {
+	return make(chan fabric.NodeSignal)
+}
+
+func TestTransport(t *testing.T) {
+	// 3 depends on 1 and 2
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{3, 1}, {3, 2}})
+	graph.Transport = fabric.NewLocalTransport(1)
+	graph.RebuildSignaling(true)
+
+	// each dependency's signals arrive on its own channel
+	nodes[2].Signal(fabric.NodeSignal{Value: fabric.Aborted})
+	nodes[1].Signal(fabric.NodeSignal{Value: fabric.Completed})
+	if sig := <-nodes[3].ListSignals()[1]; sig.Value != fabric.Completed {
+		t.Fatalf("Incorrect signal received from node 1: %v", sig.Value)
+	}
+	if sig := <-nodes[3].ListSignals()[2]; sig.Value != fabric.Aborted {
+		t.Fatalf("Incorrect signal received from node 2: %v", sig.Value)
+	}
+
+	// failed sends are counted as dropped
+	old := nodes[1].ListSignalers()[3]
+	graph.Transport = FailingTransport{}
+	graph.RebuildSignaling(true)
+	if _, open := <-old; open {
+		t.Fatal("Replaced channel is still forwarded")
+	}
+	nodes[1].Signal(fabric.NodeSignal{Value: fabric.Completed})
+	for i := 0; graph.DroppedSignals(3, 1) != 1; i++ {
+		if i == 100 {
+			t.Fatal("Failed send was not counted as dropped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// edges added later are forwarded, and removed edges stop being forwarded
+	graph.Transport = fabric.NewLocalTransport(1)
+	graph.RebuildSignaling(true)
+	graph.AddRealEdge(2, nodes[1])
+	nodes[1].ListSignalers()[2] <- fabric.NodeSignal{Value: fabric.Completed}
+	if sig := <-nodes[2].ListSignals()[1]; sig.Value != fabric.Completed {
+		t.Fatalf("Incorrect signal received through transport on new edge: %v", sig.Value)
+	}
+
+	removed := nodes[1].ListSignalers()[2]
+	graph.RemoveRealEdge(2, nodes[1])
+	if _, open := <-removed; open {
+		t.Fatal("Removed edge's channel is still forwarded")
+	}
+}
+
+func TestSignalingTopology(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1, 2, 3}, [][2]int{{2, 1}, {3, 1}, {3, 2}})
+
+	top := graph.SignalingTopology()
+	if len(top[1]) != 2 || top[1][0] != 2 || top[1][1] != 3 || len(top[2]) != 1 || len(top[3]) != 0 {
+		t.Fatalf("Incorrect signaling topology: %v", top)
+	}
+
+	if err := graph.AssertSignalingMatches(map[int][]int{1: {3, 2}, 2: {3}}); err != nil {
+		t.Fatalf("Signaling topology did not match: %v", err)
+	}
+
+	if err := graph.AssertSignalingMatches(map[int][]int{1: {2}, 2: {3}}); err == nil {
+		t.Fatal("Mismatched signaling topology was not detected")
+	}
+}
+
+func TestSignalPayload(t *testing.T) {
+	_, nodes := chainGraph(t, []int{1, 2}, [][2]int{{2, 1}})
+
+	reason := fmt.Errorf("node does not exist")
+	go nodes[1].Signal(fabric.AbortSignal(7, reason))
+
+	sig := <-nodes[2].ListSignals()[1]
+	if sig.Value != fabric.Aborted || sig.AccessType != 7 || sig.Reason() != reason {
+		t.Fatalf("Incorrect abort signal received: %v", sig)
+	}
+
+	if (fabric.NodeSignal{Payload: 5}).Reason() != nil {
+		t.Fatal("Non-error payload returned as reason")
+	}
+}
+
+func TestHealth(t *testing.T) {
+	// 1 depends on 2, 2 depends on 3
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{1, 2}, {2, 3}})
+
+	graph.ReportSignal(3, fabric.Completed)
+	graph.ReportSignal(2, fabric.Started)
+	graph.ReportSignal(4, fabric.Started)
+	graph.ReportSignal(4, fabric.Waiting)
+
+	h := graph.Health()
+	if h.States[fabric.Completed] != 1 || h.States[fabric.Started] != 1 || h.States[fabric.Waiting] != 2 {
+		t.Fatalf("Incorrect signal state counts: %v", h.States)
+	}
+	if len(h.Waiting) != 2 || h.Waiting[0] != 1 || h.Waiting[1] != 4 {
+		t.Fatalf("Incorrect longest waiting nodes: %v", h.Waiting)
+	}
+	if h.Deadlock {
+		t.Fatal("Deadlock detected in acyclic graph")
+	}
+
+	graph.AddRealEdge(3, newTestUI(1))
+	if !graph.Health().Deadlock {
+		t.Fatal("Deadlock not detected in cyclic graph")
+	}
+}
+
+func TestInFlightCount(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4}, nil)
+
+	state := map[int]fabric.Signal{
+		1: fabric.Started,
+		2: fabric.AbortRetry,
+		3: fabric.Completed,
+		5: fabric.Started, // not in graph
+	}
+	if n := graph.InFlightCount(state); n != 2 {
+		t.Fatalf("Incorrect in-flight count: %d", n)
+	}
+}
+
+func TestDrainSignals(t *testing.T) {
+	c1 := make(chan fabric.NodeSignal, 2)
+	c2 := make(chan fabric.NodeSignal)
+	c3 := make(chan fabric.NodeSignal)
+	c1 <- fabric.NodeSignal{Value: fabric.Started}
+	c1 <- fabric.NodeSignal{Value: fabric.Completed}
+	close(c3)
+
+	// blocked (unbuffered) sender
+	sent := make(chan bool)
+	go func() {
+		c2 <- fabric.NodeSignal{Value: fabric.Aborted}
+		sent <- true
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	sm := fabric.SignalsMap{1: c1, 2: c2, 3: c3}
+	signals := fabric.DrainSignals(sm)
+	if len(signals) != 3 || signals[0].Value != fabric.Started || signals[2].Value != fabric.Aborted {
+		t.Fatalf("Incorrect drained signals: %v", signals)
+	}
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("Sender was left blocked")
+	}
+
+	if len(fabric.DrainSignals(sm)) != 0 {
+		t.Fatal("Drained signals from empty channels")
+	}
+}
+
+func TestSignalClass(t *testing.T) {
+	// 1 and 2 depend on 3
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 3}, {2, 3}})
+
+	if err := graph.SetEdgeClasses(1, 3, []string{"write", "read"}); err != nil {
+		t.Fatalf("Could not set edge classes: %v", err)
+	}
+	if err := graph.SetEdgeClasses(3, 1, []string{"write"}); err == nil {
+		t.Fatal("Set classes of an edge that does not exist")
+	}
+	if fmt.Sprint(graph.EdgeClasses(1, 3)) != "[read write]" {
+		t.Fatalf("Incorrect edge classes: %v", graph.EdgeClasses(1, 3))
+	}
+
+	if d := graph.DependentsForClass(3, "read"); len(d) != 1 || d[0].ID() != 1 {
+		t.Fatalf("Incorrect dependents for class: %v", d)
+	}
+	if d := graph.DependentsForClass(3, "delete"); len(d) != 0 {
+		t.Fatalf("Incorrect dependents for class: %v", d)
+	}
+
+	received := make(chan fabric.NodeSignal, 1)
+	go func() {
+		received <- <-nodes[1].ListSignals()[3]
+	}()
+
+	sent, err := graph.SignalClass(3, "write", fabric.NodeSignal{Value: fabric.Completed})
+	if err != nil || sent != 1 {
+		t.Fatalf("Incorrectly routed class signal: %d, %v", sent, err)
+	}
+	if s := <-received; s.Value != fabric.Completed {
+		t.Fatalf("Incorrect signal received: %v", s)
+	}
+
+	if sent, _ := graph.SignalClass(3, "delete", fabric.NodeSignal{Value: fabric.Completed}); sent != 0 {
+		t.Fatal("Signal was routed to an untagged edge")
+	}
+}
+
+func TestSignalSender(t *testing.T) {
+	sender := fabric.NewSignalSender()
+	observed := make(chan time.Duration, 2)
+	sender.Observe = func(dstID int, blocked time.Duration) {
+		observed <- blocked
+	}
+
+	c := make(chan fabric.NodeSignal)
+	go sender.Send(2, c, fabric.NodeSignal{Value: fabric.Started})
+	go sender.Send(2, c, fabric.NodeSignal{Value: fabric.Completed})
+
+	// both sends block until the slow receiver reads
+	deadline := time.Now().Add(time.Second)
+	for sender.PendingSignals(2) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Incorrect pending signals: %d", sender.PendingSignals(2))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	<-c
+	<-c
+	<-observed
+	<-observed
+
+	if sender.PendingSignals(2) != 0 {
+		t.Fatalf("Incorrect pending signals after receiving: %d", sender.PendingSignals(2))
+	}
+
+	h := sender.BlockingTimes(2)
+	if h.Count != 2 || h.Sum < 40*time.Millisecond {
+		t.Fatalf("Incorrect blocking time histogram: %v", h)
+	}
+	slow := uint64(0)
+	for i, b := range h.Buckets {
+		if b > 10*time.Millisecond {
+			slow += h.Counts[i]
+		}
+	}
+	if slow != 2 {
+		t.Fatalf("Blocking times in incorrect buckets: %v", h)
+	}
+}
+
+func TestPropagatePartial(t *testing.T) {
+	// 2 and 3 depend on 1, 4 depends on 2, 5 depends on 3
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4, 5}, [][2]int{{2, 1}, {3, 1}, {4, 2}, {5, 3}})
+
+	var decided []int
+	err := graph.PropagatePartial(1, func(n fabric.DGNode) fabric.Signal {
+		decided = append(decided, n.ID())
+		if n.ID() == 2 {
+			// continue with degraded input
+			return fabric.Started
+		}
+		return fabric.Aborted
+	})
+	if err != nil {
+		t.Fatalf("Could not propagate partial abort: %v", err)
+	}
+
+	if fmt.Sprint(decided) != "[2 3 5]" {
+		t.Fatalf("Incorrect nodes decided: %v", decided)
+	}
+
+	h := graph.Health()
+	if h.States[fabric.PartialAbort] != 1 || h.States[fabric.Aborted] != 2 || h.States[fabric.Started] != 1 {
+		t.Fatalf("Incorrect recorded reactions: %v", h.States)
+	}
+
+	if err := graph.PropagatePartial(6, nil); err == nil {
+		t.Fatal("Propagated partial abort from node not in graph")
+	}
+}
+
+func TestSignalRoute(t *testing.T) {
+	// 2 depends on 1, 3 depends on 2 and 1
+	graph, nodes := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{2, 1}, {3, 2}, {3, 1}})
+
+	route, ok := graph.SignalRoute(1, 3)
+	if !ok || fmt.Sprint(route) != "[1 3]" {
+		t.Fatalf("Incorrect signal route: %v", route)
+	}
+
+	graph.RemoveRealEdge(3, nodes[1])
+	route, ok = graph.SignalRoute(1, 3)
+	if !ok || fmt.Sprint(route) != "[1 2 3]" {
+		t.Fatalf("Incorrect signal route: %v", route)
+	}
+
+	if _, ok := graph.SignalRoute(3, 1); ok {
+		t.Fatal("Signal route against the signaling direction")
+	}
+	if _, ok := graph.SignalRoute(1, 4); ok {
+		t.Fatal("Signal route to unconnected node")
+	}
+}
+
+func TestSignalingHotspots(t *testing.T) {
+	// 1 depends on 2, 3 and 4; 2 depends on 3
+	graph, nodes := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{1, 2}, {1, 3}, {1, 4}, {2, 3}})
+
+	if hot := graph.SignalingHotspots(2); len(hot) != 1 || hot[0] != 1 {
+		t.Fatalf("Incorrect signaling hotspots: %v", hot)
+	}
+	if hot := graph.SignalingHotspots(0); len(hot) != 2 {
+		t.Fatalf("Incorrect signaling hotspots: %v", hot)
+	}
+
+	// buffered channels are not hotspots
+	signals := nodes[1].ListSignals()
+	signals[4] = make(chan fabric.NodeSignal, 1)
+	if hot := graph.SignalingHotspots(2); len(hot) != 0 {
+		t.Fatalf("Incorrect signaling hotspots: %v", hot)
+	}
+}
+
+func TestAsymmetricChannels(t *testing.T) {
+	// 1 depends on 2 and 3
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}, {1, 3}})
+
+	if a := graph.AsymmetricChannels(); len(a) != 0 {
+		t.Fatalf("Incorrect asymmetric channels: %v", a)
+	}
+
+	delete(nodes[1].ListSignals(), 2)
+	delete(nodes[3].ListSignalers(), 1)
+	a := graph.AsymmetricChannels()
+	if fmt.Sprint(a) != "[{2 1 incoming} {3 1 outgoing}]" {
+		t.Fatalf("Incorrect asymmetric channels: %v", a)
+	}
+
+	// 1 depends on 2, which is missing from Top
+	graph, nodes = chainGraph(t, []int{1, 2}, [][2]int{{1, 2}})
+	delete(graph.Top, nodes[2])
+	graph.Reindex()
+	a = graph.AsymmetricChannels()
+	if fmt.Sprint(a) != "[{2 1 outgoing}]" {
+		t.Fatalf("Incorrect asymmetric channels for missing dependency: %v", a)
+	}
+}
+
+func TestSignalTier(t *testing.T) {
+	graph := fabric.NewGraph()
+	nodes := make(map[int]fabric.DGNode)
+	for id, p := range map[int]int{1: 3, 2: 2, 3: 1} {
+		n, err := graph.AddRealNode(PriorityUI{UI: newTestUI(id), Priority: p})
+		if err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+		nodes[id] = n
+	}
+
+	// 1 and 2 depend on 3
+	graph.AddRealEdge(1, nodes[3])
+	graph.AddRealEdge(2, nodes[3])
+
+	received := make(chan int, 2)
+	go func() {
+		for i := 0; i < 2; i++ {
+			select {
+			case <-nodes[1].ListSignals()[3]:
+				received <- 1
+			case <-nodes[2].ListSignals()[3]:
+				received <- 2
+			}
+		}
+	}()
+
+	if sent := graph.SignalTier(2, fabric.NodeSignal{Value: fabric.Aborted}); sent != 2 {
+		t.Fatalf("Incorrect number of signals sent: %d", sent)
+	}
+	if first := <-received; first != 1 {
+		t.Fatalf("Higher priority node was not signaled first: %d", first)
+	}
+	<-received
+
+	if sent := graph.SignalTier(4, fabric.NodeSignal{Value: fabric.Aborted}); sent != 0 {
+		t.Fatalf("Incorrect number of signals sent: %d", sent)
+	}
+}
+
+func TestAwaitRemovedDependency(t *testing.T) {
+	// 1 depends on 2 and 3
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{1, 2}, {1, 3}})
+
+	type result struct {
+		signals map[int]fabric.NodeSignal
+		removed []int
+		err     error
+	}
+	done := make(chan result)
+	go func() {
+		signals, removed, err := fabric.AwaitDependencies(context.Background(), nodes[1].ListSignals())
+		done <- result{signals, removed, err}
+	}()
+
+	nodes[2].ListSignalers()[1] <- fabric.NodeSignal{Value: fabric.Completed}
+	if err := graph.RemoveRealNode(3); err != nil {
+		t.Fatalf("Could not remove node: %v", err)
+	}
+
+	select {
+	case r := <-done:
+		if r.err != nil || r.signals[2].Value != fabric.Completed || fmt.Sprint(r.removed) != "[3]" {
+			t.Fatalf("Incorrect dependency results: %v, %v, %v", r.signals, r.removed, r.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Dependent blocked on removed dependency")
+	}
+
+	if err := graph.RemoveRealNode(3); err == nil {
+		t.Fatal("Removed node not in graph")
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := &fabric.RetryPolicy{Base: 100 * time.Millisecond, Max: time.Second}
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		if d := p.Backoff(attempt); d != want {
+			t.Fatalf("Incorrect backoff for attempt %d: %v", attempt, d)
+		}
+	}
+
+	a := &fabric.RetryPolicy{Base: time.Second, Jitter: 0.5, Seed: 7}
+	b := &fabric.RetryPolicy{Base: time.Second, Jitter: 0.5, Seed: 7}
+	varied := false
+	for i := 0; i < 10; i++ {
+		d := a.Backoff(0)
+		if d != b.Backoff(0) {
+			t.Fatal("Jitter is not reproducible from the seed")
+		}
+		if d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Fatalf("Jittered backoff out of range: %v", d)
+		}
+		if d != time.Second {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Fatal("Backoff was not jittered")
+	}
+}
+
+func TestDeliveryMode(t *testing.T) {
+	// 1 depends on 2
+	graph, nodes := chainGraph(t, []int{1, 2}, [][2]int{{1, 2}})
+
+	if err := graph.SetDeliveryMode(1, 2, fabric.AtMostOnce); err != nil {
+		t.Fatalf("Could not set delivery mode: %v", err)
+	}
+	if err := graph.Deliver(1, 2, fabric.NodeSignal{Value: fabric.Started}); err == nil {
+		t.Fatal("At-most-once signal without a receiver was not dropped")
+	}
+	if graph.DroppedSignals(1, 2) != 1 {
+		t.Fatalf("Incorrect dropped signals: %d", graph.DroppedSignals(1, 2))
+	}
+
+	graph.DeliveryTimeout = 20 * time.Millisecond
+
+	graph.SetDeliveryMode(1, 2, fabric.AtLeastOnce)
+	received := make(chan fabric.NodeSignal, 1)
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		received <- <-nodes[1].ListSignals()[2]
+	}()
+	if err := graph.Deliver(1, 2, fabric.NodeSignal{Value: fabric.Aborted}); err != nil {
+		t.Fatalf("At-least-once signal was not delivered: %v", err)
+	}
+	if s := <-received; s.Value != fabric.Aborted || graph.DroppedSignals(1, 2) != 1 {
+		t.Fatalf("Incorrect delivery: %v, %d dropped", s, graph.DroppedSignals(1, 2))
+	}
+
+	if err := graph.SetDeliveryMode(2, 1, fabric.AtLeastOnce); err == nil {
+		t.Fatal("Set delivery mode of an edge that does not exist")
+	}
+}
+
+func TestCoalesceSignals(t *testing.T) {
+	graph := fabric.NewGraph()
+	graph.CoalesceSignals = true
+	nodes := make(map[int]fabric.DGNode)
+	for _, id := range []int{1, 2} {
+		n, err := graph.AddRealNode(newTestUI(id))
+		if err != nil {
+			t.Fatalf("Could not add UI node to graph: %v", err)
+		}
+		nodes[id] = n
+	}
+	// 1 depends on 2
+	graph.AddRealEdge(1, nodes[2])
+
+	for _, s := range []fabric.Signal{fabric.Started, fabric.AbortRetry, fabric.Completed} {
+		if err := graph.Deliver(1, 2, fabric.NodeSignal{Value: s}); err != nil {
+			t.Fatalf("Could not deliver signal: %v", err)
+		}
+	}
+
+	c := nodes[1].ListSignals()[2]
+	if s := <-c; s.Value != fabric.Completed {
+		t.Fatalf("Signals were not coalesced: %v", s)
+	}
+	select {
+	case s := <-c:
+		t.Fatalf("Stale signal was received: %v", s)
+	default:
+	}
+}
+
+func TestSignalAwaitAck(t *testing.T) {
+	// 2 and 3 depend on 1
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, [][2]int{{2, 1}, {3, 1}})
+
+	// only 2 processes the signal
+	go func() {
+		s := <-nodes[2].ListSignals()[1]
+		s.Acknowledge(2)
+	}()
+
+	acked, missed := graph.SignalAwaitAck(1, fabric.NodeSignal{Value: fabric.Completed}, 50*time.Millisecond)
+	if fmt.Sprint(acked) != "[2]" || fmt.Sprint(missed) != "[3]" {
+		t.Fatalf("Incorrect acknowledgements: %v, %v", acked, missed)
+	}
+}
@@ -0,0 +1,157 @@
+// +build test
+
+package fabric_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/JKhawaja/fabric"
+)
+
+func TestTransaction(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1, 2}, [][2]int{{1, 2}})
+
+	// failed transactions leave the graph untouched
+	err := graph.Transaction(func(tx *fabric.GraphTx) error {
+		if err := tx.AddNode(newTestUI(3)); err != nil {
+			return err
+		}
+		if err := tx.AddEdge(3, 1); err != nil {
+			return err
+		}
+		return fmt.Errorf("validation failed")
+	})
+	if err == nil {
+		t.Fatal("Transaction error was not returned")
+	}
+	if _, ok := graph.GetNode(3); ok || len(graph.Top) != 2 {
+		t.Fatal("Failed transaction modified the graph")
+	}
+
+	// invalid operations are rejected
+	err = graph.Transaction(func(tx *fabric.GraphTx) error {
+		return tx.AddEdge(1, 4)
+	})
+	if err == nil {
+		t.Fatal("Edge to unknown node was accepted")
+	}
+
+	// successful transactions are applied with channel wiring
+	err = graph.Transaction(func(tx *fabric.GraphTx) error {
+		if err := tx.AddNode(newTestUI(3)); err != nil {
+			return err
+		}
+		if err := tx.AddEdge(3, 1); err != nil {
+			return err
+		}
+		return tx.RemoveEdge(1, 2)
+	})
+	if err != nil {
+		t.Fatalf("Could not apply transaction: %v", err)
+	}
+
+	n1, _ := graph.GetNode(1)
+	n3, ok := graph.GetNode(3)
+	if !ok || len(graph.Dependencies(n3)) != 1 || len(graph.Dependencies(n1)) != 0 {
+		t.Fatal("Transaction was not applied correctly")
+	}
+	if _, ok := n1.ListSignalers()[3]; !ok {
+		t.Fatal("Signaling channel was not wired")
+	}
+	if _, ok := n1.ListSignals()[2]; ok {
+		t.Fatal("Signaling channel was not removed")
+	}
+}
+
+func TestAddEdgesAsync(t *testing.T) {
+	graph, nodes := chainGraph(t, []int{1, 2, 3}, nil)
+
+	if err := <-graph.AddEdgesAsync([][2]int{{1, 2}, {2, 4}}); err == nil {
+		t.Fatal("Added an edge to a node that does not exist")
+	}
+	if graph.Stats().Edges != 0 {
+		t.Fatal("Failed edge addition modified the graph")
+	}
+
+	if err := <-graph.AddEdgesAsync([][2]int{{1, 2}, {2, 3}}); err != nil {
+		t.Fatalf("Could not add edges: %v", err)
+	}
+	if graph.Stats().Edges != 2 {
+		t.Fatalf("Incorrect number of edges: %d", graph.Stats().Edges)
+	}
+	if _, ok := nodes[1].ListSignals()[2]; !ok {
+		t.Fatal("Signaling channel was not wired")
+	}
+}
+
+func TestTransactionCyclePolicy(t *testing.T) {
+	// 2 depends on 1
+	graph, _ := chainGraph(t, []int{1, 2, 3}, [][2]int{{2, 1}})
+	graph.CyclePolicy = fabric.Reject
+
+	err := graph.Transaction(func(tx *fabric.GraphTx) error {
+		if err := tx.AddEdge(3, 2); err != nil {
+			return err
+		}
+		return tx.AddEdge(1, 2)
+	})
+	if !errors.Is(err, fabric.ErrCycle) {
+		t.Fatalf("Incorrect error for cyclic edge: %v", err)
+	}
+	if graph.Stats().Edges != 1 {
+		t.Fatal("Failed transaction modified the graph")
+	}
+
+	// cycles through buffered edges are rejected as well
+	err = graph.Transaction(func(tx *fabric.GraphTx) error {
+		if err := tx.AddEdge(3, 2); err != nil {
+			return err
+		}
+		return tx.AddEdge(1, 3)
+	})
+	if !errors.Is(err, fabric.ErrCycle) || graph.Stats().Edges != 1 {
+		t.Fatalf("Cycle through buffered edge was not rejected: %v", err)
+	}
+	if err := <-graph.AddEdgesAsync([][2]int{{3, 2}, {1, 3}}); !errors.Is(err, fabric.ErrCycle) {
+		t.Fatalf("Incorrect error for cyclic edges: %v", err)
+	}
+}
+
+func TestTransactionMaxDepth(t *testing.T) {
+	// 2 depends on 1
+	graph, _ := chainGraph(t, []int{1, 2, 3, 4}, [][2]int{{2, 1}})
+	graph.MaxDepth = 1
+
+	if err := <-graph.AddEdgesAsync([][2]int{{3, 2}}); err == nil {
+		t.Fatal("Edge exceeding the maximum depth was added")
+	}
+
+	// chains through buffered edges are limited as well
+	if err := <-graph.AddEdgesAsync([][2]int{{4, 3}, {3, 1}}); err == nil {
+		t.Fatal("Edges exceeding the maximum depth were added")
+	}
+	if graph.Stats().Edges != 1 {
+		t.Fatal("Failed transaction modified the graph")
+	}
+}
+
+func TestTransactionStrict(t *testing.T) {
+	graph, _ := chainGraph(t, []int{1}, nil)
+	graph.Strict = true
+
+	// UI nodes without a section are rejected while validating
+	err := graph.Transaction(func(tx *fabric.GraphTx) error {
+		if err := tx.AddNode(newTestUI(2)); err != nil {
+			return err
+		}
+		return tx.AddEdge(1, 2)
+	})
+	if err == nil {
+		t.Fatal("Added a UI node without a section in strict mode")
+	}
+	if _, ok := graph.GetNode(2); ok {
+		t.Fatal("Failed transaction modified the graph")
+	}
+}
@@ -3,6 +3,8 @@
 package fabric_test
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/JKhawaja/fabric"
@@ -201,3 +203,228 @@ func TestVDG(t *testing.T) {
 		}
 	}
 }
+
+// LiveVirtual is a Virtual node with a lifecycle
+type LiveVirtual struct {
+	Virtual
+	Life *fabric.Life
+}
+
+func (v LiveVirtual) Lifecycle() fabric.Life {
+	return *v.Life
+}
+
+func (v LiveVirtual) SetLifecycle(l fabric.Life) {
+	*v.Life = l
+}
+
+func newLiveVirtual(id int, life fabric.Life) LiveVirtual {
+	sm := make(fabric.SignalingMap)
+	s := make(fabric.SignalsMap)
+	return LiveVirtual{
+		Virtual: Virtual{
+			Node: Node{
+				Id:        id,
+				Type:      fabric.VDGNode,
+				Signalers: &sm,
+				Signals:   &s,
+			},
+			Space: newTestUI(0),
+		},
+		Life: &life,
+	}
+}
+
+func TestLifecycleSignaling(t *testing.T) {
+	graph := fabric.NewGraph()
+	vdg, err := fabric.NewVDG(graph)
+	if err != nil {
+		t.Fatalf("Could not create VDG and add to graph: %v", err)
+	}
+
+	src := newLiveVirtual(1, fabric.Running)
+	idle := newLiveVirtual(2, fabric.Idle)
+	running := newLiveVirtual(3, fabric.Running)
+	for _, v := range []LiveVirtual{src, idle, running} {
+		if _, err := vdg.AddVirtualNode(v); err != nil {
+			t.Fatalf("Could not add Virtual node to VDG: %v", err)
+		}
+	}
+	vdg.AddVirtualEdge(2, src)
+	vdg.AddVirtualEdge(3, src)
+
+	if len(graph.ActiveVirtuals()) != 2 {
+		t.Fatalf("Incorrect active virtual nodes: %v", graph.ActiveVirtuals())
+	}
+
+	go func() {
+		<-running.ListSignals()[1]
+	}()
+	sent, err := vdg.SignalVirtual(1, fabric.NodeSignal{Value: fabric.Completed})
+	if err != nil || sent != 1 {
+		t.Fatalf("Incorrectly gated signals: %d, %v", sent, err)
+	}
+
+	*src.Life = fabric.Complete
+	if _, err := vdg.SignalVirtual(1, fabric.NodeSignal{Value: fabric.Completed}); err == nil {
+		t.Fatal("Completed virtual node was allowed to signal")
+	}
+}
+
+func TestRestoreLifecycles(t *testing.T) {
+	graph := fabric.NewGraph()
+	vdg, err := fabric.NewVDG(graph)
+	if err != nil {
+		t.Fatalf("Could not create VDG and add to graph: %v", err)
+	}
+
+	idle := newLiveVirtual(1, fabric.Idle)
+	running := newLiveVirtual(2, fabric.Running)
+	for _, v := range []LiveVirtual{idle, running} {
+		if _, err := vdg.AddVirtualNode(v); err != nil {
+			t.Fatalf("Could not add Virtual node to VDG: %v", err)
+		}
+	}
+
+	snapshot := graph.LifecycleSnapshot()
+	if len(snapshot) != 2 || snapshot[1] != fabric.Idle || snapshot[2] != fabric.Running {
+		t.Fatalf("Incorrect lifecycle snapshot: %v", snapshot)
+	}
+
+	*idle.Life = fabric.Complete
+	*running.Life = fabric.Complete
+	if err := graph.RestoreLifecycles(map[int]fabric.Life{1: fabric.Idle, 3: fabric.Running}); err == nil {
+		t.Fatal("Restored lifecycle of unknown virtual node")
+	}
+	if *idle.Life != fabric.Complete {
+		t.Fatal("Failed restore modified lifecycles")
+	}
+
+	if err := graph.RestoreLifecycles(snapshot); err != nil {
+		t.Fatalf("Could not restore lifecycles: %v", err)
+	}
+	if *idle.Life != fabric.Idle || *running.Life != fabric.Running {
+		t.Fatal("Lifecycles were not restored")
+	}
+}
+
+func TestLifecycleManagerRunContext(t *testing.T) {
+	lm := fabric.NewLifecycleManager()
+
+	// cancelled work goes back to Idle
+	ctx, cancel := context.WithCancel(context.Background())
+	err := lm.RunContext(ctx, func(ctx context.Context) error {
+		if lm.Lifecycle() != fabric.Running {
+			t.Fatal("Work is not Running")
+		}
+		cancel()
+		<-ctx.Done()
+		return nil
+	})
+	if err != context.Canceled || lm.Lifecycle() != fabric.Idle {
+		t.Fatalf("Incorrect state after cancellation: %v, %v", err, lm.Lifecycle())
+	}
+
+	if err := lm.RunContext(context.Background(), func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("Could not run work: %v", err)
+	}
+	if lm.Lifecycle() != fabric.Complete {
+		t.Fatalf("Incorrect state after success: %v", lm.Lifecycle())
+	}
+
+	// Complete nodes can not be run again
+	if err := lm.RunContext(context.Background(), func(context.Context) error { return nil }); err == nil {
+		t.Fatal("Complete node was run again")
+	}
+}
+
+func TestMarshalStateful(t *testing.T) {
+	build := func() (*fabric.Graph, LiveVirtual) {
+		graph, _ := chainGraph(t, []int{1, 2}, [][2]int{{1, 2}})
+		vdg, err := fabric.NewVDG(graph)
+		if err != nil {
+			t.Fatalf("Could not create VDG and add to graph: %v", err)
+		}
+		v := newLiveVirtual(3, fabric.Idle)
+		if _, err := vdg.AddVirtualNode(v); err != nil {
+			t.Fatalf("Could not add Virtual node to VDG: %v", err)
+		}
+		return graph, v
+	}
+
+	graph, v := build()
+	graph.ReportSignal(2, fabric.Completed)
+	*v.Life = fabric.Running
+
+	topology, err := json.Marshal(graph)
+	if err != nil {
+		t.Fatalf("Could not marshal graph: %v", err)
+	}
+	if string(topology) != `{"nodes":[{"id":1,"type":"UI","priority":1},{"id":2,"type":"UI","priority":1}],"edges":[[1,2]]}` {
+		t.Fatalf("Incorrect graph JSON: %s", topology)
+	}
+
+	data, err := graph.MarshalStateful()
+	if err != nil {
+		t.Fatalf("Could not marshal graph state: %v", err)
+	}
+
+	restored, rv := build()
+	if err := restored.RestoreStateful(data); err != nil {
+		t.Fatalf("Could not restore graph state: %v", err)
+	}
+	if *rv.Life != fabric.Running || restored.Health().States[fabric.Completed] != 1 {
+		t.Fatal("Graph state was not restored")
+	}
+
+	other, _ := chainGraph(t, []int{1, 3}, nil)
+	if err := other.RestoreStateful(data); err == nil {
+		t.Fatal("Restored state onto a different topology")
+	}
+}
+
+func TestPromote(t *testing.T) {
+	graph := fabric.NewGraph()
+	vdg, err := fabric.NewVDG(graph)
+	if err != nil {
+		t.Fatalf("Could not create VDG and add to graph: %v", err)
+	}
+
+	done1 := newLiveVirtual(1, fabric.Complete)
+	done2 := newLiveVirtual(2, fabric.Complete)
+	running := newLiveVirtual(3, fabric.Running)
+	for _, v := range []LiveVirtual{done1, done2, running} {
+		if _, err := vdg.AddVirtualNode(v); err != nil {
+			t.Fatalf("Could not add Virtual node to VDG: %v", err)
+		}
+	}
+	vdg.AddVirtualEdge(2, done1)
+
+	convert := func(v fabric.Virtual) fabric.DGNode {
+		return newTestUI(v.ID())
+	}
+
+	if err := graph.Promote(vdg, []int{3}, convert); err == nil {
+		t.Fatal("Promoted a virtual node that is not Complete")
+	}
+	if err := graph.Promote(vdg, []int{1}, convert); err == nil {
+		t.Fatal("Promoted a virtual node without its virtual dependents")
+	}
+	if len(graph.Top) != 0 {
+		t.Fatal("Failed promotion modified graph")
+	}
+
+	if err := graph.Promote(vdg, []int{1, 2}, convert); err != nil {
+		t.Fatalf("Could not promote virtual nodes: %v", err)
+	}
+	n2, ok := graph.GetNode(2)
+	if !ok || len(graph.Dependencies(n2)) != 1 || graph.Dependencies(n2)[0].ID() != 1 {
+		t.Fatal("Virtual nodes were not promoted with their edges")
+	}
+	if graph.IsVirtual(2) {
+		t.Fatal("Promoted node is still virtual")
+	}
+	if len(vdg.Top) != 1 {
+		t.Fatalf("Promoted nodes were not removed from VDG: %d nodes left", len(vdg.Top))
+	}
+}
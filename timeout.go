@@ -0,0 +1,62 @@
+package fabric
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetNodeTimeout sets the maximum duration a node may run (between starting
+// its watchdog and stopping it) before an Aborted signal is automatically
+// sent to its dependents on its behalf (Run enforces it with a context
+// deadline instead). A duration <= 0 removes the timeout.
+func (g *Graph) SetNodeTimeout(id int, d time.Duration) {
+	if d <= 0 {
+		delete(g.timeouts, id)
+		return
+	}
+
+	if g.timeouts == nil {
+		g.timeouts = make(map[int]time.Duration)
+	}
+	g.timeouts[id] = d
+}
+
+// NodeTimeout returns the timeout set for a node (if any)
+func (g *Graph) NodeTimeout(id int) (time.Duration, bool) {
+	d, ok := g.timeouts[id]
+	return d, ok
+}
+
+// StartWatchdog should be called by a node's thread when the node starts
+// execution. If the returned stop function has not been called (usually right
+// after signaling a terminal state) within the node's timeout, Aborted
+// is signaled to all of the node's dependents, which propagate it further
+// as usual. The stop function returns
+// false if the watchdog already fired. Nodes without a timeout get a no-op
+// watchdog.
+func (g *Graph) StartWatchdog(id int) (func() bool, error) {
+	node, ok := g.GetNode(id)
+	if !ok {
+		return nil, fmt.Errorf("Node %d: %w", id, ErrNodeNotFound)
+	}
+
+	d, ok := g.timeouts[id]
+	if !ok {
+		return func() bool { return true }, nil
+	}
+
+	timer := time.AfterFunc(d, func() {
+		// dependents react to the abort (e.g. by aborting themselves) as to
+		// any other signal; ones that do not receive it within another
+		// timeout period are skipped rather than blocking forever
+		g.ReportSignal(id, Aborted)
+		for _, c := range node.ListSignalers() {
+			select {
+			case c <- NodeSignal{Value: Aborted}:
+			case <-time.After(d):
+			}
+		}
+	})
+
+	return timer.Stop, nil
+}
@@ -0,0 +1,255 @@
+package fabric
+
+import "fmt"
+
+// GraphTx buffers topology changes to a graph so that they can be applied
+// as a single unit (see Graph.Transaction). Every operation is validated
+// against the graph plus all previously buffered operations.
+type GraphTx struct {
+	g     *Graph
+	ops   []txOp
+	nodes map[int]DGNode
+	edges map[[2]int]bool
+}
+
+// txOp is a buffered operation of a GraphTx along with how to undo it
+type txOp struct {
+	apply func() error
+	undo  func()
+}
+
+// Transaction calls fn with a new GraphTx and only applies the buffered
+// operations (including signaling channel wiring) to the graph if fn returns
+// nil; otherwise all operations are discarded and the graph is left untouched.
+// If the graph rejects an operation while it is being applied, the operations
+// applied before it are undone and the graph's error is returned.
+func (g *Graph) Transaction(fn func(tx *GraphTx) error) error {
+	tx := &GraphTx{
+		g:     g,
+		nodes: make(map[int]DGNode),
+		edges: make(map[[2]int]bool),
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	for i, op := range tx.ops {
+		if err := op.apply(); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				tx.ops[j].undo()
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// node returns the node with the given id from the graph or the transaction
+func (tx *GraphTx) node(id int) (DGNode, bool) {
+	if n, ok := tx.nodes[id]; ok {
+		return n, true
+	}
+	return tx.g.GetNode(id)
+}
+
+// hasEdge checks whether an edge exists once all buffered operations are applied
+func (tx *GraphTx) hasEdge(source, dest int) bool {
+	if e, ok := tx.edges[[2]int{source, dest}]; ok {
+		return e
+	}
+
+	if n, ok := tx.g.GetNode(source); ok {
+		for _, d := range tx.g.Top[n] {
+			if d.ID() == dest {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dependencyIDs returns the ids of the dependencies of a node once all
+// buffered operations are applied
+func (tx *GraphTx) dependencyIDs(id int) []int {
+	var ids []int
+	for _, d := range tx.g.dependencyIDs(id) {
+		if e, ok := tx.edges[[2]int{id, d}]; !ok || e {
+			ids = append(ids, d)
+		}
+	}
+	for e, added := range tx.edges {
+		if added && e[0] == id && !tx.g.hasEdge(id, e[1]) {
+			ids = append(ids, e[1])
+		}
+	}
+	return ids
+}
+
+// dependentIDs returns the ids of the dependents of a node once all
+// buffered operations are applied
+func (tx *GraphTx) dependentIDs(id int) []int {
+	var ids []int
+	for _, d := range tx.g.dependentIDs(id) {
+		if e, ok := tx.edges[[2]int{d, id}]; !ok || e {
+			ids = append(ids, d)
+		}
+	}
+	for e, added := range tx.edges {
+		if added && e[1] == id && !tx.g.hasEdge(e[0], id) {
+			ids = append(ids, e[0])
+		}
+	}
+	return ids
+}
+
+// chainThrough returns the number of edges in the longest dependency chain
+// that would contain a new edge from source to dest once all buffered
+// operations are applied (see Graph.MaxDepth)
+func (tx *GraphTx) chainThrough(source, dest int) int {
+	up := tx.g.chainLength(source, tx.dependentIDs)
+	down := tx.g.chainLength(dest, tx.dependencyIDs)
+	return up + 1 + down
+}
+
+// wouldCycle checks whether adding an edge from source to dest would create
+// a cycle once all buffered operations are applied (see Graph.WouldCycle)
+func (tx *GraphTx) wouldCycle(source, dest int) bool {
+	visited := map[int]bool{dest: true}
+	stack := []int{dest}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if id == source {
+			return true
+		}
+		for _, d := range tx.dependencyIDs(id) {
+			if !visited[d] {
+				visited[d] = true
+				stack = append(stack, d)
+			}
+		}
+	}
+	return false
+}
+
+// AddNode buffers the addition of a node to the graph
+func (tx *GraphTx) AddNode(node DGNode) error {
+	if _, ok := tx.node(node.ID()); ok {
+		return fmt.Errorf("Node %d: %w", node.ID(), ErrNodeExists)
+	}
+
+	if err := checkHashable(node); err != nil {
+		return err
+	}
+	if tx.g.Strict {
+		if err := tx.g.strictNode(node); err != nil {
+			return err
+		}
+	}
+
+	tx.nodes[node.ID()] = node
+	tx.ops = append(tx.ops, txOp{
+		apply: func() error {
+			_, err := tx.g.AddRealNode(node)
+			return err
+		},
+		undo: func() {
+			if n, ok := tx.g.GetNode(node.ID()); ok {
+				tx.g.removeNode(n)
+			}
+		},
+	})
+
+	return nil
+}
+
+// AddEdge buffers the addition of an edge (and its signaling channel)
+// from the source node to the destination (dependency) node
+func (tx *GraphTx) AddEdge(source, dest int) error {
+	if _, ok := tx.node(source); !ok {
+		return fmt.Errorf("Source node %d: %w", source, ErrNodeNotFound)
+	}
+	if _, ok := tx.node(dest); !ok {
+		return fmt.Errorf("Destination node %d: %w", dest, ErrNodeNotFound)
+	}
+	if tx.hasEdge(source, dest) {
+		return fmt.Errorf("Edge from %d to %d already exists in Dependency Graph", source, dest)
+	}
+	if tx.g.MaxDepth > 0 {
+		if l := tx.chainThrough(source, dest); l > tx.g.MaxDepth {
+			return fmt.Errorf("Edge from %d to %d would create a dependency chain of length %d (maximum depth is %d)", source, dest, l, tx.g.MaxDepth)
+		}
+	}
+	if tx.g.CyclePolicy == Reject && tx.wouldCycle(source, dest) {
+		return fmt.Errorf("Edge from %d to %d: %w", source, dest, ErrCycle)
+	}
+
+	tx.edges[[2]int{source, dest}] = true
+	tx.ops = append(tx.ops, txOp{
+		apply: func() error {
+			d, ok := tx.g.GetNode(dest)
+			if !ok {
+				return fmt.Errorf("Destination node %d: %w", dest, ErrNodeNotFound)
+			}
+			return tx.g.AddRealEdgeChecked(source, d)
+		},
+		undo: func() {
+			if d, ok := tx.g.GetNode(dest); ok {
+				tx.g.RemoveRealEdge(source, d)
+			}
+		},
+	})
+
+	return nil
+}
+
+// RemoveEdge buffers the removal of an edge (and its signaling channel)
+func (tx *GraphTx) RemoveEdge(source, dest int) error {
+	if !tx.hasEdge(source, dest) {
+		return fmt.Errorf("Edge from %d to %d does not exist in Dependency Graph", source, dest)
+	}
+
+	tx.edges[[2]int{source, dest}] = false
+	tx.ops = append(tx.ops, txOp{
+		apply: func() error {
+			if d, ok := tx.g.GetNode(dest); ok {
+				tx.g.RemoveRealEdge(source, d)
+			}
+			return nil
+		},
+		undo: func() {
+			if d, ok := tx.g.GetNode(dest); ok {
+				tx.g.addRealEdge(source, d)
+			}
+		},
+	})
+
+	return nil
+}
+
+// AddEdgesAsync validates and adds the given (source id, destination id)
+// edges, including their signaling channel wiring, on a background goroutine.
+// The returned channel receives nil once every edge has been added, or the
+// first error (in which case no edges are added, see Transaction), and is then
+// closed. The graph must not be used until a value has been received.
+func (g *Graph) AddEdgesAsync(edges [][2]int) <-chan error {
+	done := make(chan error, 1)
+
+	go func() {
+		defer close(done)
+
+		done <- g.Transaction(func(tx *GraphTx) error {
+			for _, e := range edges {
+				if err := tx.AddEdge(e[0], e[1]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}()
+
+	return done
+}
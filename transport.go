@@ -0,0 +1,72 @@
+package fabric
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Transport abstracts how signals are delivered between dependency graph
+// nodes; e.g. for a distributed deployment where signaling needs to cross
+// process boundaries. Endpoints are keyed by the (source, destination) node
+// pair of the signal, so that a node receives the signals of each of its
+// dependencies on a separate channel (as with a SignalsMap): Send delivers a
+// signal from the node with id srcID to the node with id dstID, and Recv
+// returns the channel node dstID receives the signals of node srcID on.
+type Transport interface {
+	Send(srcID, dstID int, sig NodeSignal) error
+	Recv(srcID, dstID int) <-chan NodeSignal
+}
+
+// LocalTransport is the in-process, channel-based Transport implementation
+type LocalTransport struct {
+	mu     sync.Mutex
+	inbox  map[[2]int]chan NodeSignal
+	Buffer int // buffer size of each receive channel
+}
+
+// NewLocalTransport ...
+func NewLocalTransport(buffer int) *LocalTransport {
+	return &LocalTransport{
+		inbox:  make(map[[2]int]chan NodeSignal),
+		Buffer: buffer,
+	}
+}
+
+func (t *LocalTransport) channel(srcID, dstID int) chan NodeSignal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.inbox == nil {
+		t.inbox = make(map[[2]int]chan NodeSignal)
+	}
+
+	c, ok := t.inbox[[2]int{srcID, dstID}]
+	if !ok {
+		c = make(chan NodeSignal, t.Buffer)
+		t.inbox[[2]int{srcID, dstID}] = c
+	}
+	return c
+}
+
+// Send ...
+func (t *LocalTransport) Send(srcID, dstID int, sig NodeSignal) error {
+	t.channel(srcID, dstID) <- sig
+	return nil
+}
+
+// Recv ...
+func (t *LocalTransport) Recv(srcID, dstID int) <-chan NodeSignal {
+	return t.channel(srcID, dstID)
+}
+
+// forward will send every signal received on the signaling channel from
+// node srcID to node dstID through the transport until the channel is
+// closed; signals the transport fails to send are counted as dropped on
+// the edge (see DroppedSignals)
+func forward(t Transport, srcID, dstID int, c <-chan NodeSignal, m *edgeMeta) {
+	for sig := range c {
+		if err := t.Send(srcID, dstID, sig); err != nil && m != nil {
+			atomic.AddInt64(&m.dropped, 1)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package fabric
 
+import "math/rand"
+
 /*
 	A UI is the generic interface that can be satisfied when
 	generating UIs from a CDS.
@@ -21,6 +23,7 @@ type UI interface {
 // EmptyUI can be used when a UI is needed codewise, but the CDS system will not be using
 // any spatial virtualization (UI DDAGs).
 type EmptyUI struct {
+	Id               int
 	AccessProcedures *ProcedureList
 	Signalers        *SignalingMap
 	Signals          *SignalsMap
@@ -55,7 +58,7 @@ func NewTotalUI(section Section) UI {
 
 // ID ...
 func (u *EmptyUI) ID() int {
-	return 0
+	return u.Id
 }
 
 // GetType ...
@@ -113,6 +116,73 @@ func (u *EmptyUI) IsVirtual() bool {
 	return false
 }
 
+// GenerateUIs will create 'count' UI nodes whose sections together cover
+// approximately the requested fraction (0 to 1) of the CDS nodes; a random
+// selection of CDS nodes is distributed across the UIs, and every CDS edge
+// between two selected nodes is added to the section of its source node's UI.
+// The same seed will always generate the same UIs (including ids); this is mainly
+// useful for exercising coverage logic in tests.
+func GenerateUIs(c CDS, count int, coverage float64, seed int64) []UI {
+	var uis []UI
+	if count <= 0 {
+		return uis
+	}
+
+	if coverage < 0 {
+		coverage = 0
+	} else if coverage > 1 {
+		coverage = 1
+	}
+
+	r := rand.New(rand.NewSource(seed))
+
+	// select CDS nodes
+	nodes := c.ListNodes()
+	selected := int(coverage*float64(len(nodes)) + 0.5)
+	perm := r.Perm(len(nodes))[:selected]
+
+	nodeLists := make([]NodeList, count)
+	owner := make(map[int]int)
+	for i, p := range perm {
+		n := nodes[p]
+		nodeLists[i%count] = append(nodeLists[i%count], n)
+		owner[n.ID()] = i % count
+	}
+
+	// assign edges between selected nodes
+	edgeLists := make([]EdgeList, count)
+	for _, e := range c.ListEdges() {
+		u, ok := owner[e.GetSource().ID()]
+		if _, ok2 := owner[e.GetDestination().ID()]; ok && ok2 {
+			edgeLists[u] = append(edgeLists[u], e)
+		}
+	}
+
+	ids := make(map[int]bool)
+	for i := 0; i < count; i++ {
+		id := r.Int()
+		for ids[id] {
+			id = r.Int()
+		}
+		ids[id] = true
+
+		nl := nodeLists[i]
+		if nl == nil {
+			nl = make(NodeList, 0)
+		}
+		el := edgeLists[i]
+		if el == nil {
+			el = make(EdgeList, 0)
+		}
+
+		ui := NewTotalUI(NewDisjoint(&nl, &el)).(*EmptyUI)
+		ui.Id = id
+		uis = append(uis, ui)
+	}
+
+	return uis
+}
+
 // NOTE: VUIs can be part of VUI Dependency Graphs
 //	but each VUI *must* have a lifespan shorter than its dependents.
 //	A VUI can have both real and virtual dependents and it can
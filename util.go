@@ -1,5 +1,7 @@
 package fabric
 
+import "sort"
+
 // contains checks if DGNode is already in DGNode slice or not
 func contains(s []DGNode, i DGNode) bool {
 	for _, v := range s {
@@ -19,10 +21,23 @@ func containsVirtual(s []Virtual, i Virtual) bool {
 	return false
 }
 
+// NodeEqual is used to determine whether two CDS nodes are the same node
+// (e.g. by the section constructors when de-duplicating nodes); it can be
+// replaced to support e.g. nodes with composite ids. Defaults to comparing ids.
+var NodeEqual = func(a, b Node) bool {
+	return a.ID() == b.ID()
+}
+
+// EdgeEqual is used to determine whether two CDS edges are the same edge;
+// it can be replaced in the same way as NodeEqual. Defaults to comparing ids.
+var EdgeEqual = func(a, b Edge) bool {
+	return a.ID() == b.ID()
+}
+
 // ContainsNode checks if a CDS node (reference) is in a NodeList
 func ContainsNode(l NodeList, n Node) bool {
 	for _, v := range l {
-		if v.ID() == n.ID() {
+		if NodeEqual(v, n) {
 			return true
 		}
 	}
@@ -32,9 +47,27 @@ func ContainsNode(l NodeList, n Node) bool {
 // ContainsEdge checks if a CDS edge (reference) is in an Edglist
 func ContainsEdge(l EdgeList, e Edge) bool {
 	for _, v := range l {
-		if v.ID() == e.ID() {
+		if EdgeEqual(v, e) {
 			return true
 		}
 	}
 	return false
 }
+
+// sortNodes sorts a DGNode slice in place by node id; used to give
+// deterministic results from functions that range over map values
+func sortNodes(s []DGNode) {
+	sort.Slice(s, func(i, j int) bool {
+		return s[i].ID() < s[j].ID()
+	})
+}
+
+// sortEdges sorts a list of (source id, destination id) pairs in place
+func sortEdges(s [][2]int) {
+	sort.Slice(s, func(i, j int) bool {
+		if s[i][0] != s[j][0] {
+			return s[i][0] < s[j][0]
+		}
+		return s[i][1] < s[j][1]
+	})
+}
@@ -187,8 +187,13 @@ func NewVDGWithRoot(g *Graph) (*VDG, error) {
 
 // GenID can generate a unique integer id for a VDG node
 func (g *VDG) GenID() int {
-	rand.Seed(time.Now().UnixNano())
-	id := rand.Int()
+	var id int
+	if g.Global != nil {
+		id = g.Global.randInt()
+	} else {
+		rand.Seed(time.Now().UnixNano())
+		id = rand.Int()
+	}
 	for n := range g.Top {
 		if n.ID() == id {
 			id = g.GenID()
@@ -227,11 +232,15 @@ func (g *VDG) Signals(n Virtual) SignalsMap {
 // TotalBlock is the most basic format for signal checking
 // (used when a node wants to simply totally-block all further operations until its dependencies have signaled)
 // as it only accepts a BasicSignalHandler it will not be a very powerful form of blocking (only use if lazy)
+// A node that is not Running can not receive signals; TotalBlock will return false for it without blocking.
 func (g *VDG) TotalBlock(nodeID int, handler BasicSignalHandler) bool {
 	var wg sync.WaitGroup
 
 	for n := range g.Top {
 		if n.ID() == nodeID {
+			if lifeOf(n) != Running {
+				return false
+			}
 			depSignals := n.ListSignals()
 			for _, channel := range depSignals {
 				wg.Add(1)
@@ -284,7 +293,7 @@ func (g *VDG) AddVirtualNode(node Virtual) (Virtual, error) {
 	if _, ok := g.Top[node]; !ok {
 		g.Top[node] = []Virtual{}
 	} else {
-		return ret, fmt.Errorf("Node already exists in Dependency Graph.")
+		return ret, fmt.Errorf("Node %d: %w", node.ID(), ErrNodeExists)
 	}
 	// Add node's subspace to graph
 	g.Space = append(g.Space, node.Subspace().ID())
@@ -301,7 +310,7 @@ func (g *VDG) AddTopNode(node Virtual) error {
 	if _, ok := g.Top[node]; !ok {
 		g.Top[node] = []Virtual{}
 	} else {
-		return fmt.Errorf("Node already exists in Dependency Graph.")
+		return fmt.Errorf("Node %d: %w", node.ID(), ErrNodeExists)
 	}
 
 	// Add node's subspace to graph
@@ -325,7 +334,7 @@ func (g *VDG) RemoveVirtualNode(n Virtual) error {
 	for node, list := range g.Top {
 		if node.ID() == n.ID() {
 			if len(list) > 0 {
-				return fmt.Errorf("Virtual node still has dependencies. Cannot be deleted.")
+				return fmt.Errorf("Virtual node %d cannot be deleted: %w", n.ID(), ErrHasDependencies)
 			}
 		}
 	}
@@ -453,3 +462,84 @@ func (g *VDG) cycleDfs(start Virtual, seen, done []Virtual) (bool, []Virtual) {
 	done = append(done, start)
 	return false, done
 }
+
+// Promote moves Complete virtual nodes (see LifecycleNode) from a VDG into
+// the real graph as permanent nodes, converting each with convert (which must
+// keep the node's id and return a non-virtual node). Edges between promoted
+// nodes become real edges with new signaling channels; a node with edges to
+// virtual nodes that are not being promoted can not be promoted. Either all
+// nodes are promoted or none are.
+func (g *Graph) Promote(vdg *VDG, ids []int, convert func(Virtual) DGNode) error {
+	if vdg == nil {
+		return fmt.Errorf("No VDG to promote nodes from")
+	}
+
+	virtuals := make(map[int]Virtual)
+	for n := range vdg.Top {
+		virtuals[n.ID()] = n
+	}
+
+	promoted := make(map[int]Virtual)
+	for _, id := range ids {
+		v, ok := virtuals[id]
+		if !ok {
+			return fmt.Errorf("Virtual node %d: %w", id, ErrNodeNotFound)
+		}
+		if lifeOf(v) != Complete {
+			return fmt.Errorf("Virtual node %d can not be promoted before it is Complete", id)
+		}
+		promoted[id] = v
+	}
+
+	// promoted nodes must not be left with edges into the VDG
+	var edges [][2]int
+	for id, v := range promoted {
+		for _, d := range vdg.Dependencies(v) {
+			if _, ok := promoted[d.ID()]; !ok {
+				return fmt.Errorf("Virtual node %d depends on virtual node %d which is not being promoted", id, d.ID())
+			}
+			edges = append(edges, [2]int{id, d.ID()})
+		}
+		for _, d := range vdg.Dependents(v) {
+			if _, ok := promoted[d.ID()]; !ok {
+				return fmt.Errorf("Virtual node %d is a dependency of virtual node %d which is not being promoted", id, d.ID())
+			}
+		}
+	}
+	sortEdges(edges)
+
+	err := g.Transaction(func(tx *GraphTx) error {
+		for _, id := range ids {
+			node := convert(promoted[id])
+			if node == nil || node.ID() != id {
+				return fmt.Errorf("Virtual node %d was not converted to a node with the same id", id)
+			}
+			if isVirtualType(g.Type(node)) {
+				return fmt.Errorf("Virtual node %d was converted to a virtual node", id)
+			}
+			if err := tx.AddNode(node); err != nil {
+				return err
+			}
+		}
+		for _, e := range edges {
+			if err := tx.AddEdge(e[0], e[1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range edges {
+		vdg.RemoveVirtualEdge(e[0], promoted[e[1]])
+	}
+	for _, id := range ids {
+		if err := vdg.RemoveVirtualNode(promoted[id]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}